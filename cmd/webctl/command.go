@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// dispatch runs the subcommand named by the first argument.
+func dispatch(usage string, commands map[string]func(args []string) error, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			fmt.Print(usage)
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n\n%s", args[0], usage)
+		os.Exit(2)
+	}
+	return command(args[1:])
+}
+
+// parseRef parses a command's flags, along with the single argument referring to what it acts on.
+// Flags may come before or after the argument.
+func parseRef(flags *flag.FlagSet, args []string, name string) string {
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: webctl %s [flags] %s\n", flags.Name(), name)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	ref := flags.Arg(0)
+	flags.Parse(flags.Args()[1:])
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	return ref
+}
+
+// readOrEdit reads a document from a file, or if no file is given, has the user write it in their editor
+// starting from the template.
+func readOrEdit(file string, template string, extension string) (string, error) {
+	if file != "" {
+		return readDocument(file)
+	}
+	return edit(template, extension)
+}
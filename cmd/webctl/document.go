@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// separator ends the fields of a post document, and starts its content.
+const separator = "---"
+
+// formatPost formats a post as a document for editing. Posts and likes are edited as documents of 'key: value'
+// fields. The fields of a post are followed by a separator line, then its Markdown content, so content can be
+// edited without escaping.
+func formatPost(post types.Post) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "title: %s\n", post.Title)
+	fmt.Fprintf(&b, "slug: %s\n", post.Slug)
+	fmt.Fprintf(&b, "draft: %t\n", post.Draft)
+	fmt.Fprintf(&b, "listed: %t\n", post.Listed)
+	fmt.Fprintf(&b, "published: %s\n", formatTime(post.Published))
+	fmt.Fprintf(&b, "tags: %s\n", strings.Join(post.Tags, ", "))
+	b.WriteString(separator + "\n")
+	b.WriteString(post.Content)
+	if post.Content != "" && !strings.HasSuffix(post.Content, "\n") {
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// parsePost parses a post document over a base post. Fields left out keep their value from the base.
+func parsePost(doc string, base types.Post) (types.Post, error) {
+	fields, content, found := strings.Cut("\n"+doc, "\n"+separator+"\n")
+	if !found {
+		fields, found = strings.CutSuffix("\n"+strings.TrimRight(doc, "\n"), "\n"+separator)
+		if !found {
+			return types.Post{}, fmt.Errorf("missing '%s' line between fields and content", separator)
+		}
+	}
+	// A newline was prepended so the separator can be the first line, and must not shift line numbers
+	fields = strings.TrimPrefix(fields, "\n")
+
+	post := base
+	err := parseFields(fields, func(key string, value string) error {
+		var err error
+		switch key {
+		case "title":
+			post.Title = value
+		case "slug":
+			post.Slug = value
+		case "draft":
+			post.Draft, err = strconv.ParseBool(value)
+		case "listed":
+			post.Listed, err = strconv.ParseBool(value)
+		case "published":
+			post.Published, err = parseTime(value)
+		case "tags":
+			post.Tags = parseTags(value)
+		default:
+			return fmt.Errorf("unknown field '%s'", key)
+		}
+		return err
+	})
+	if err != nil {
+		return types.Post{}, err
+	}
+	post.Content = strings.TrimLeft(content, "\n")
+	return post, nil
+}
+
+// formatLike formats a like as a document for editing.
+func formatLike(like types.Like) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "title: %s\n", like.Title)
+	fmt.Fprintf(&b, "url: %s\n", like.URL)
+	fmt.Fprintf(&b, "timestamp: %s\n", formatTime(like.Timestamp))
+	return b.String()
+}
+
+// parseLike parses a like document over a base like. Fields left out keep their value from the base.
+func parseLike(doc string, base types.Like) (types.Like, error) {
+	like := base
+	err := parseFields(doc, func(key string, value string) error {
+		var err error
+		switch key {
+		case "title":
+			like.Title = value
+		case "url":
+			like.URL = value
+		case "timestamp":
+			like.Timestamp, err = parseTime(value)
+		default:
+			return fmt.Errorf("unknown field '%s'", key)
+		}
+		return err
+	})
+	if err != nil {
+		return types.Like{}, err
+	}
+	return like, nil
+}
+
+// parseFields calls set with each 'key: value' line, skipping blank lines.
+func parseFields(fields string, set func(key string, value string) error) error {
+	scanner := bufio.NewScanner(strings.NewReader(fields))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		key, value, found := strings.Cut(text, ":")
+		if !found {
+			return fmt.Errorf("line %d: expected 'key: value'", line)
+		}
+		if err := set(strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func parseTags(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// parseTime parses an RFC 3339 time. An empty value is the zero time.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected time like '%s'", time.RFC3339)
+	}
+	return t, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+func TestPostDocument(t *testing.T) {
+	published := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	// ==================== Test case 1: Posts round trip ====================
+	post := types.Post{
+		Title:     "Title: with a colon",
+		Slug:      "title",
+		Draft:     true,
+		Listed:    true,
+		Published: published,
+		Tags:      []string{"go", "web"},
+		Content:   "Intro\n\n---\n\nAfter a rule\n",
+	}
+	actual, err := parsePost(formatPost(post), types.Post{})
+	if err != nil {
+		t.Fatalf("failed to parse post; %s", err)
+	}
+	if actual.Title != post.Title || actual.Slug != post.Slug || actual.Draft != post.Draft || actual.Listed != post.Listed {
+		t.Errorf("expected %+v, got %+v", post, actual)
+	}
+	if !actual.Published.Equal(published) || !slices.Equal(actual.Tags, post.Tags) || actual.Content != post.Content {
+		t.Errorf("expected %+v, got %+v", post, actual)
+	}
+
+	// ==================== Test case 2: Fields left out keep their value from the base ====================
+	actual, err = parsePost("---\n# Content only\n", post)
+	if err != nil {
+		t.Fatalf("failed to parse post; %s", err)
+	}
+	if actual.Title != post.Title || !actual.Published.Equal(published) || actual.Content != "# Content only\n" {
+		t.Errorf("expected fields from base with new content, got %+v", actual)
+	}
+	actual, err = parsePost("title: Empty\n---", types.Post{})
+	if err != nil {
+		t.Fatalf("failed to parse post; %s", err)
+	}
+	if actual.Title != "Empty" || actual.Content != "" {
+		t.Errorf("expected empty content, got %+v", actual)
+	}
+
+	// ==================== Test case 3: Invalid documents ====================
+	invalid := map[string]string{
+		"title: No separator\n":           "missing",
+		"title: ok\ncolour: blue\n---\n":  "line 2: unknown field",
+		"published: yesterday\n---\n":     "line 1: expected time",
+		"draft: maybe\n---\n":             "line 1",
+		"title: ok\nno colon here\n---\n": "line 2: expected 'key: value'",
+	}
+	for doc, message := range invalid {
+		if _, err := parsePost(doc, types.Post{}); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("expected error containing '%s' parsing %q, got %v", message, doc, err)
+		}
+	}
+}
+
+func TestLikeDocument(t *testing.T) {
+	like := types.Like{Title: "A link", URL: "https://example.com/a?b=c", Timestamp: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	actual, err := parseLike(formatLike(like), types.Like{})
+	if err != nil {
+		t.Fatalf("failed to parse like; %s", err)
+	}
+	if actual.Title != like.Title || actual.URL != like.URL || !actual.Timestamp.Equal(like.Timestamp) {
+		t.Errorf("expected %+v, got %+v", like, actual)
+	}
+
+	if _, err := parseLike("title: ok\nid: 1\n", types.Like{}); err == nil || !strings.Contains(err.Error(), "unknown field 'id'") {
+		t.Errorf("expected unknown field error, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// errUnchanged is returned when a document is saved without changes, so there is nothing to write.
+var errUnchanged = errors.New("no changes made")
+
+// edit opens a document in the user's editor, and returns the document once the editor exits.
+// The editor is taken from $VISUAL or $EDITOR, defaulting to vi.
+func edit(doc string, extension string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	file, err := os.CreateTemp("", "webctl-*"+extension)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file; %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(doc); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write temporary file; %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temporary file; %w", err)
+	}
+
+	// The editor may include arguments, such as 'code --wait'
+	args := append(strings.Fields(editor), file.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor '%s' failed; %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read temporary file; %w", err)
+	}
+	if string(edited) == doc {
+		return "", errUnchanged
+	}
+	return string(edited), nil
+}
+
+// readDocument reads a document from a file, or from stdin if the path is '-'.
+func readDocument(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read '%s'; %w", path, err)
+	}
+	return string(data), nil
+}
+
+// confirm asks the user a yes or no question, defaulting to no.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	var answer string
+	fmt.Scanln(&answer)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+const likesUsage = `Usage: webctl likes <command> [flags]
+
+Commands:
+  list           List likes, most recent first
+  show <id>      Show a like
+  create         Create a like in $EDITOR, or from -file
+  edit <id>      Edit a like in $EDITOR, or from -file
+  delete <id>    Delete a like
+`
+
+func likes(args []string) error {
+	return dispatch(likesUsage, map[string]func([]string) error{
+		"list":   listLikes,
+		"show":   showLike,
+		"create": createLike,
+		"edit":   editLike,
+		"delete": deleteLike,
+	}, args)
+}
+
+func listLikes(args []string) error {
+	flags := flag.NewFlagSet("likes list", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print as JSON")
+	flags.Parse(args)
+
+	_, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	likes, err := store.GetLikes()
+	if err != nil {
+		return types.WrapErr(err, "failed to get likes")
+	}
+
+	if *asJSON {
+		return printJSON(os.Stdout, likes)
+	}
+	rows := make([][]string, len(likes))
+	for i, like := range likes {
+		rows[i] = []string{like.ID, formatDate(like.Timestamp), like.Title, like.URL}
+	}
+	return printTable(os.Stdout, []string{"ID", "TIMESTAMP", "TITLE", "URL"}, rows)
+}
+
+func showLike(args []string) error {
+	flags := flag.NewFlagSet("likes show", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print as JSON")
+	id := parseRef(flags, args, "<id>")
+
+	_, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	like, err := store.GetLike(id)
+	if err != nil {
+		return types.WrapErr(err, fmt.Sprintf("failed to get like '%s'", id))
+	}
+	if *asJSON {
+		return printJSON(os.Stdout, like)
+	}
+	fmt.Printf("id: %s\n%s", like.ID, formatLike(like))
+	return nil
+}
+
+func createLike(args []string) error {
+	flags := flag.NewFlagSet("likes create", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the created like as JSON")
+	file := flags.String("file", "", "read the like from a file, or '-' for stdin, instead of $EDITOR")
+	flags.Parse(args)
+
+	_, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	template := types.Like{Timestamp: time.Now()}
+	doc, err := readOrEdit(*file, formatLike(template), ".txt")
+	if err != nil {
+		return err
+	}
+	like, err := parseLike(doc, template)
+	if err != nil {
+		return types.WrapErr(err, "failed to parse like")
+	}
+	if err := like.Validate(); err != nil {
+		return types.WrapErr(err, "invalid like")
+	}
+	if like.Timestamp.IsZero() {
+		like.Timestamp = time.Now()
+	}
+
+	like.ID, err = store.AddLike(like)
+	if err != nil {
+		return types.WrapErr(err, "failed to create like")
+	}
+	if *asJSON {
+		return printJSON(os.Stdout, like)
+	}
+	fmt.Printf("Created like %s\n", like.ID)
+	return nil
+}
+
+func editLike(args []string) error {
+	flags := flag.NewFlagSet("likes edit", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the edited like as JSON")
+	file := flags.String("file", "", "read the like from a file, or '-' for stdin, instead of $EDITOR")
+	id := parseRef(flags, args, "<id>")
+
+	_, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	current, err := store.GetLike(id)
+	if err != nil {
+		return types.WrapErr(err, fmt.Sprintf("failed to get like '%s'", id))
+	}
+	doc, err := readOrEdit(*file, formatLike(current), ".txt")
+	if err != nil {
+		return err
+	}
+	like, err := parseLike(doc, current)
+	if err != nil {
+		return types.WrapErr(err, "failed to parse like")
+	}
+	if err := like.Validate(); err != nil {
+		return types.WrapErr(err, "invalid like")
+	}
+	like.ID = current.ID
+	if like.Timestamp.IsZero() {
+		like.Timestamp = current.Timestamp
+	}
+
+	if err := store.PutLike(like); err != nil {
+		return types.WrapErr(err, "failed to update like")
+	}
+	if *asJSON {
+		return printJSON(os.Stdout, like)
+	}
+	fmt.Printf("Updated like %s\n", like.ID)
+	return nil
+}
+
+func deleteLike(args []string) error {
+	flags := flag.NewFlagSet("likes delete", flag.ExitOnError)
+	yes := flags.Bool("yes", false, "delete without asking for confirmation")
+	id := parseRef(flags, args, "<id>")
+
+	_, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	like, err := store.GetLike(id)
+	if err != nil {
+		return types.WrapErr(err, fmt.Sprintf("failed to get like '%s'", id))
+	}
+	if !*yes && !confirm(fmt.Sprintf("Delete like '%s' (%s)?", like.Title, like.ID)) {
+		return errors.New("not deleted")
+	}
+	if err := store.DeleteLike(like.ID); err != nil {
+		return types.WrapErr(err, "failed to delete like")
+	}
+	fmt.Printf("Deleted like %s\n", like.ID)
+	return nil
+}
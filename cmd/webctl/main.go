@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: webctl <command> [flags]
+
+Commands:
+  posts      List, show, create, edit, publish, unpublish, and delete posts
+  likes      List, show, create, edit, and delete likes
+  export     Write every post and like to an archive
+  import     Create or update posts and likes from an archive
+  restore    Restore posts and likes from a backup archive in the backup bucket
+
+Commands act directly on the store selected by the config, so set ENVIRONMENT,
+STORAGE_BACKEND, and SQLITE_PATH as for the server.
+
+Run 'webctl <command> -h' for a command's flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "posts":
+		err = posts(os.Args[2:])
+	case "likes":
+		err = likes(os.Args[2:])
+	case "export":
+		err = exportArchive(os.Args[2:])
+	case "import":
+		err = importArchive(os.Args[2:])
+	case "restore":
+		err = restore(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
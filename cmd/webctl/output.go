@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/render"
+	"github.com/georgemblack/web-api/pkg/repo"
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// openStore loads the config, then opens the store it selects.
+func openStore() (conf.Config, repo.Store, error) {
+	config, err := conf.LoadConfig()
+	if err != nil {
+		return conf.Config{}, nil, types.WrapErr(err, "failed to load config")
+	}
+	store, err := repo.NewStore(config)
+	if err != nil {
+		return conf.Config{}, nil, types.WrapErr(err, "failed to create store")
+	}
+	return config, store, nil
+}
+
+// renderPost renders a post's content, as the API does on write, and warns about any HTML removed by the sanitizer.
+func renderPost(config conf.Config, post types.Post) (types.Post, error) {
+	post, report, err := render.NewRenderer(config).Post(post)
+	if err != nil {
+		return types.Post{}, types.WrapErr(err, "failed to render post")
+	}
+	for _, removal := range report {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", describeRemoval(removal))
+	}
+	return post, nil
+}
+
+// describeRemoval describes markup stripped from content by the sanitizer.
+func describeRemoval(removal render.Removal) string {
+	element := "<" + removal.Element + ">"
+	if removal.Attribute != "" {
+		element += " attribute '" + removal.Attribute + "'"
+	}
+	return fmt.Sprintf("removed %s from content %d time(s)", element, removal.Count)
+}
+
+func printJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
+}
+
+// printTable writes rows aligned into columns beneath a header.
+func printTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// postStatus describes whether a post is visible on the site.
+func postStatus(post types.Post, now time.Time) string {
+	switch {
+	case post.Draft:
+		return "draft"
+	case post.Published.After(now):
+		return "scheduled"
+	case !post.Listed:
+		return "unlisted"
+	}
+	return "published"
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/repo"
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+const postsUsage = `Usage: webctl posts <command> [flags]
+
+Commands:
+  list                     List posts, most recently published first
+  show <id|slug>           Show a post
+  create                   Create a post in $EDITOR, or from -file
+  edit <id|slug>           Edit a post in $EDITOR, or from -file
+  publish <id|slug>        Mark a post as no longer a draft
+  unpublish <id|slug>      Mark a post as a draft
+  delete <id|slug>         Delete a post
+`
+
+func posts(args []string) error {
+	return dispatch(postsUsage, map[string]func([]string) error{
+		"list":      listPosts,
+		"show":      showPost,
+		"create":    createPost,
+		"edit":      editPost,
+		"publish":   func(args []string) error { return setDraft("publish", args, false) },
+		"unpublish": func(args []string) error { return setDraft("unpublish", args, true) },
+		"delete":    deletePost,
+	}, args)
+}
+
+func listPosts(args []string) error {
+	flags := flag.NewFlagSet("posts list", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print as JSON")
+	published := flags.Bool("published", false, "only list published posts")
+	listed := flags.Bool("listed", false, "only list listed posts")
+	flags.Parse(args)
+
+	_, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var filters repo.PostFilters
+	if *published {
+		filters.Published = published
+	}
+	if *listed {
+		filters.Listed = listed
+	}
+	posts, err := store.GetPosts(filters)
+	if err != nil {
+		return types.WrapErr(err, "failed to get posts")
+	}
+
+	if *asJSON {
+		return printJSON(os.Stdout, posts)
+	}
+	now := time.Now()
+	rows := make([][]string, len(posts))
+	for i, post := range posts {
+		rows[i] = []string{post.ID, postStatus(post, now), formatDate(post.Published), strconv.Itoa(post.WordCount), post.Slug, post.Title}
+	}
+	return printTable(os.Stdout, []string{"ID", "STATUS", "PUBLISHED", "WORDS", "SLUG", "TITLE"}, rows)
+}
+
+func showPost(args []string) error {
+	flags := flag.NewFlagSet("posts show", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print as JSON")
+	ref := parseRef(flags, args, "<id|slug>")
+
+	_, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	post, err := findPost(store, ref)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(os.Stdout, post)
+	}
+	fmt.Printf("id: %s\nupdated: %s\nstatus: %s\n%s", post.ID, formatTime(post.Updated), postStatus(post, time.Now()), formatPost(post))
+	return nil
+}
+
+func createPost(args []string) error {
+	flags := flag.NewFlagSet("posts create", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the created post as JSON")
+	file := flags.String("file", "", "read the post from a file, or '-' for stdin, instead of $EDITOR")
+	flags.Parse(args)
+
+	config, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	template := types.Post{Draft: true, Listed: true, Published: time.Now(), Tags: []string{}}
+	doc, err := readOrEdit(*file, formatPost(template), ".md")
+	if err != nil {
+		return err
+	}
+	post, err := parsePost(doc, template)
+	if err != nil {
+		return types.WrapErr(err, "failed to parse post")
+	}
+	post, err = renderPost(config, post)
+	if err != nil {
+		return err
+	}
+
+	id, err := store.AddPost(post)
+	if err != nil {
+		return types.WrapErr(err, "failed to create post")
+	}
+	return printPost(store, id, "Created", *asJSON)
+}
+
+// editPost replaces a post with the edited document. If the post changes while it's being edited,
+// the edit is rejected rather than overwriting the other change.
+func editPost(args []string) error {
+	flags := flag.NewFlagSet("posts edit", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the edited post as JSON")
+	file := flags.String("file", "", "read the post from a file, or '-' for stdin, instead of $EDITOR")
+	ref := parseRef(flags, args, "<id|slug>")
+
+	config, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	current, err := findPost(store, ref)
+	if err != nil {
+		return err
+	}
+	doc, err := readOrEdit(*file, formatPost(current), ".md")
+	if err != nil {
+		return err
+	}
+	post, err := parsePost(doc, current)
+	if err != nil {
+		return types.WrapErr(err, "failed to parse post")
+	}
+	post, err = renderPost(config, post)
+	if err != nil {
+		return err
+	}
+
+	_, err = store.UpdatePost(post)
+	if errors.Is(err, repo.ErrPreconditionFailed) {
+		return fmt.Errorf("post '%s' changed while it was being edited; %w", post.ID, err)
+	}
+	if err != nil {
+		return types.WrapErr(err, "failed to update post")
+	}
+	return printPost(store, post.ID, "Updated", *asJSON)
+}
+
+// setDraft marks a post as a draft, or not. Publishing a post without a publish date publishes it now.
+func setDraft(name string, args []string, draft bool) error {
+	flags := flag.NewFlagSet("posts "+name, flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the post as JSON")
+	ref := parseRef(flags, args, "<id|slug>")
+
+	_, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	current, err := findPost(store, ref)
+	if err != nil {
+		return err
+	}
+	patch := types.Post{ID: current.ID, Draft: draft, Updated: current.Updated, Published: time.Now()}
+	fields := []string{"draft"}
+	if !draft && current.Published.IsZero() {
+		fields = append(fields, "published")
+	}
+	post, err := store.PatchPost(patch, fields)
+	if err != nil {
+		return types.WrapErr(err, fmt.Sprintf("failed to %s post", name))
+	}
+
+	if *asJSON {
+		return printJSON(os.Stdout, post)
+	}
+	fmt.Printf("Post %s is now %s\n", post.ID, postStatus(post, time.Now()))
+	return nil
+}
+
+func deletePost(args []string) error {
+	flags := flag.NewFlagSet("posts delete", flag.ExitOnError)
+	yes := flags.Bool("yes", false, "delete without asking for confirmation")
+	ref := parseRef(flags, args, "<id|slug>")
+
+	_, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	post, err := findPost(store, ref)
+	if err != nil {
+		return err
+	}
+	if !*yes && !confirm(fmt.Sprintf("Delete post '%s' (%s)?", post.Title, post.ID)) {
+		return errors.New("not deleted")
+	}
+	if err := store.DeletePost(post.ID); err != nil {
+		return types.WrapErr(err, "failed to delete post")
+	}
+	fmt.Printf("Deleted post %s\n", post.ID)
+	return nil
+}
+
+// findPost gets a post by its ID, or else by its slug.
+func findPost(store repo.Store, ref string) (types.Post, error) {
+	post, err := store.GetPost(ref)
+	if errors.Is(err, repo.ErrNotFound) {
+		post, err = store.GetPostBySlug(ref, repo.PostFilters{})
+	}
+	if err != nil {
+		return types.Post{}, types.WrapErr(err, fmt.Sprintf("failed to get post '%s'", ref))
+	}
+	return post, nil
+}
+
+// printPost prints a post after it's been written, as stored.
+func printPost(store repo.Store, id string, verb string, asJSON bool) error {
+	if !asJSON {
+		fmt.Printf("%s post %s\n", verb, id)
+		return nil
+	}
+	post, err := store.GetPost(id)
+	if err != nil {
+		return types.WrapErr(err, "failed to get post")
+	}
+	return printJSON(os.Stdout, post)
+}
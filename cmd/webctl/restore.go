@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/georgemblack/web-api/pkg/backup"
+	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/render"
+	"github.com/georgemblack/web-api/pkg/repo"
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// restore restores a backup archive directly to the configured store, so it works even while the API is down.
+// The archive is read from a local file with -file, or else from the backup bucket by name, defaulting to the most recent.
+func restore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report changes without writing anything")
+	file := flags.String("file", "", "path of a local archive to restore, instead of one from the backup bucket")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: webctl restore [-dry-run] [-file path | name]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() > 1 || (*file != "" && flags.NArg() > 0) {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	config, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var archive backup.Archive
+	var name string
+	if *file != "" {
+		archive, name, err = readArchiveFile(*file)
+	} else {
+		archive, name, err = readArchiveBlob(store, config, flags.Arg(0))
+	}
+	if err != nil {
+		return types.WrapErr(err, "failed to read archive")
+	}
+	return restoreArchive(store, config, archive, name, *dryRun, *asJSON)
+}
+
+// restoreArchive restores an archive that has been read, then prints the report. A restore that fails partway
+// through may already have written some records, so its report is printed before the error is returned.
+func restoreArchive(store repo.Store, config conf.Config, archive backup.Archive, name string, dryRun bool, asJSON bool) error {
+	report, err := backup.RestoreArchive(store, render.NewRenderer(config), archive, dryRun)
+	report.Name = name
+	if err := printReport(os.Stdout, report, asJSON); err != nil {
+		return err
+	}
+	if err != nil {
+		return types.WrapErr(err, "failed to restore")
+	}
+	return nil
+}
+
+// readArchiveFile reads an archive from a file, or from stdin if the path is '-', returning it along with its name.
+func readArchiveFile(path string) (backup.Archive, string, error) {
+	if path == "-" {
+		archive, err := backup.ReadArchive(os.Stdin)
+		return archive, "stdin", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return backup.Archive{}, "", err
+	}
+	defer f.Close()
+	archive, err := backup.ReadArchive(f)
+	return archive, path, err
+}
+
+// readArchiveBlob reads an archive from the backup bucket by name, or the most recent if the name is empty.
+func readArchiveBlob(store repo.Store, config conf.Config, name string) (backup.Archive, string, error) {
+	ctx := context.Background()
+	blobs, err := backup.NewBlobStore(ctx, config)
+	if err != nil {
+		return backup.Archive{}, "", err
+	}
+	defer blobs.Close()
+	return backup.NewService(store, blobs, render.NewRenderer(config), backup.Namespace(config)).Archive(ctx, name)
+}
+
+func printReport(w io.Writer, report backup.RestoreReport, asJSON bool) error {
+	if asJSON {
+		return printJSON(w, report)
+	}
+
+	verb := "Restored"
+	if report.DryRun {
+		verb = "Dry run of"
+	}
+	fmt.Fprintf(w, "%s %s\n", verb, report.Name)
+	var rows [][]string
+	for _, section := range []struct {
+		name    string
+		changes backup.Changes
+	}{{"posts", report.Posts}, {"likes", report.Likes}} {
+		rows = append(rows, []string{section.name, fmt.Sprint(len(section.changes.Created)), fmt.Sprint(len(section.changes.Updated)), fmt.Sprint(len(section.changes.Conflicts))})
+	}
+	if err := printTable(w, []string{"", "CREATED", "UPDATED", "CONFLICTS"}, rows); err != nil {
+		return err
+	}
+	for _, changes := range []backup.Changes{report.Posts, report.Likes} {
+		for _, conflict := range changes.Conflicts {
+			fmt.Fprintf(w, "conflict %s: %s\n", conflict.ID, conflict.Reason)
+		}
+	}
+	ids := make([]string, 0, len(report.Sanitized))
+	for id := range report.Sanitized {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		for _, removal := range report.Sanitized[id] {
+			fmt.Fprintf(w, "sanitized %s: %s\n", id, describeRemoval(removal))
+		}
+	}
+	return nil
+}
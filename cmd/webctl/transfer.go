@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/georgemblack/web-api/pkg/backup"
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// importArchive restores an archive from a file, or from stdin if no file is given, such as one written by export.
+func importArchive(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report changes without writing anything")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: webctl import [-dry-run] [file]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() > 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	path := flags.Arg(0)
+	if path == "" {
+		path = "-"
+	}
+
+	config, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	archive, name, err := readArchiveFile(path)
+	if err != nil {
+		return types.WrapErr(err, "failed to read archive")
+	}
+	return restoreArchive(store, config, archive, name, *dryRun, *asJSON)
+}
+
+// exportArchive writes every post and like to an archive in the same format as backups, to a file or stdout.
+func exportArchive(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "write the archive to a file instead of stdout")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	_, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return types.WrapErr(err, "failed to create archive")
+		}
+		defer f.Close()
+		w = f
+	}
+	manifest, err := backup.WriteArchive(w, store)
+	if err != nil {
+		return types.WrapErr(err, "failed to export")
+	}
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d posts and %d likes to %s\n", manifest.Files[0].Records, manifest.Files[1].Records, *output)
+	}
+	return nil
+}
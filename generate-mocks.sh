@@ -1,2 +1,2 @@
 go install go.uber.org/mock/mockgen@latest
-mockgen -source=pkg/api/api.go -destination=pkg/testutil/mocks.go -package=testutil
+mockgen -source=pkg/repo/store.go -destination=pkg/testutil/mocks.go -package=testutil
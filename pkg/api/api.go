@@ -1,43 +1,64 @@
 package api
 
 import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/backup"
+	"github.com/georgemblack/web-api/pkg/build"
 	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/render"
 	"github.com/georgemblack/web-api/pkg/repo"
 	"github.com/georgemblack/web-api/pkg/types"
 	"github.com/gin-gonic/gin"
 )
 
-type FirestoreService interface {
-	GetLike(id string) (types.Like, error)
-	GetLikes() ([]types.Like, error)
-	AddLike(like types.Like) (string, error)
-	DeleteLike(id string) error
-	GetPost(id string) (types.Post, error)
-	GetPosts(filters repo.PostFilters) ([]types.Post, error)
-	AddPost(post types.Post) (string, error)
-	UpdatePost(post types.Post) error
-	DeletePost(id string) error
-	Close()
-}
-
 func Run() error {
 	conf, err := conf.LoadConfig()
 	if err != nil {
 		return types.WrapErr(err, "failed to load config")
 	}
 
-	var firestore FirestoreService
-	firestore, err = repo.NewFirestoreService(conf)
+	store, err := repo.NewStore(conf)
 	if err != nil {
-		return types.WrapErr(err, "failed to create firestore service")
+		return types.WrapErr(err, "failed to create store")
 	}
+	defer store.Close()
 
-	r := setupRouter(conf, firestore)
+	delay := build.DefaultDelay
+	if conf.BuildDelaySeconds > 0 {
+		delay = time.Duration(conf.BuildDelaySeconds) * time.Second
+	}
+	builds := build.NewTrigger(conf.BuildServiceEndpoint, delay)
+	defer builds.Close()
+
+	// Backups are disabled if their destination can't be reached, such as when running locally without credentials
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var backups *backup.Service
+	blobs, err := backup.NewBlobStore(ctx, conf)
+	if err != nil {
+		slog.Warn(types.WrapErr(err, "backups disabled").Error())
+	} else {
+		defer blobs.Close()
+		backups = backup.NewService(store, blobs, render.NewRenderer(conf), backup.Namespace(conf))
+
+		// Backups are normally scheduled by calling the backup endpoint, as every instance would run its own schedule,
+		// and instances scaled to zero run none
+		if conf.BackupIntervalHours > 0 {
+			go backups.Schedule(ctx, time.Duration(conf.BackupIntervalHours)*time.Hour)
+		}
+	}
+
+	r := setupRouter(conf, store, builds, backups)
 
 	return r.Run()
 }
 
-func setupRouter(conf conf.Config, fs FirestoreService) *gin.Engine {
+func setupRouter(conf conf.Config, store repo.Store, builds *build.Trigger, backups *backup.Service) *gin.Engine {
+	renderer := render.NewRenderer(conf)
+
 	r := gin.Default()
 	r.Use(headerMiddleware(conf))
 	r.Use(requestIDMiddleware())
@@ -49,13 +70,36 @@ func setupRouter(conf conf.Config, fs FirestoreService) *gin.Engine {
 	// Auth endpoint, required to fetch a JWT
 	r.POST("/auth", authHandler(conf))
 
+	// Public endpoints
+	r.GET("/openapi.json", openAPIHandler())
+	r.GET("/styles/highlight.css", highlightStyleHandler())
+
 	// Standard endpoints
 	// All standard endpoints require a valid JWT
 	authorized := r.Group("/", validateJWTMiddleware(conf))
-	authorized.GET("/likes", getLikesHandler(fs))
-	authorized.GET("/likes/:id", getLikeHandler(fs))
-	authorized.GET("/posts", getPostsHandler(fs))
-	authorized.PUT("/posts/:id", updatePostHandler(fs))
+	authorized.GET("/likes", getLikesHandler(store))
+	authorized.GET("/likes/:id", getLikeHandler(store))
+	authorized.GET("/posts", getPostsHandler(store))
+	authorized.GET("/posts/:id", getPostHandler(store, renderer))
+	authorized.GET("/posts/by-slug/:slug", getPostBySlugHandler(store, renderer))
+	authorized.GET("/posts/:id/revisions", getRevisionsHandler(store))
+	authorized.GET("/posts/:id/revisions/:rev", getRevisionHandler(store))
+	authorized.GET("/builds", getBuildsHandler(builds))
+
+	// Content mutations
+	// Successful mutations trigger a build of the static site
+	mutations := authorized.Group("/", buildMiddleware(builds))
+	mutations.POST("/likes", addLikeHandler(store))
+	mutations.DELETE("/likes/:id", deleteLikeHandler(store))
+	mutations.POST("/posts", addPostHandler(store, renderer))
+	mutations.PUT("/posts/:id", updatePostHandler(store, renderer))
+	mutations.PATCH("/posts/:id", patchPostHandler(store, renderer))
+	mutations.DELETE("/posts/:id", deletePostHandler(store))
+	mutations.POST("/posts/:id/revisions/:rev/restore", restoreRevisionHandler(store))
+	mutations.POST("/admin/restore", restoreHandler(backups))
+
+	// Admin endpoints
+	authorized.POST("/admin/backups", backupHandler(backups))
 
 	return r
 }
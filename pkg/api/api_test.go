@@ -0,0 +1,77 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/georgemblack/web-api/pkg/build"
+	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/repo"
+	"github.com/georgemblack/web-api/pkg/testutil"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRouterWithMemoryStore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config, err := conf.LoadConfig()
+	if err != nil {
+		t.Errorf("failed to load config: %v", err)
+	}
+	config.StorageBackend = "memory"
+
+	store, err := repo.NewStore(config)
+	if err != nil {
+		t.Errorf("failed to create store: %v", err)
+	}
+	router := setupRouter(config, store, build.NewTrigger("", 0), nil)
+	token := testutil.GetJWT(config, router)
+
+	request := func(method string, path string, body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// ==================== Test case 1: Create post ====================
+	post := testutil.NewPost()
+	body, _ := json.Marshal(post)
+	w := request("POST", "/posts", body)
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status code 201, got %d", w.Code)
+	}
+	location := w.Header().Get("Location")
+
+	// ==================== Test case 2: Get created post ====================
+	w = request("GET", location, nil)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+
+	// ==================== Test case 3: Get created post by slug ====================
+	w = request("GET", "/posts/by-slug/"+post.Slug, nil)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+
+	// ==================== Test case 4: Duplicate slug ====================
+	w = request("POST", "/posts", body)
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected status code 409, got %d", w.Code)
+	}
+
+	// ==================== Test case 5: Delete post ====================
+	w = request("DELETE", location, nil)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected status code 204, got %d", w.Code)
+	}
+	w = request("GET", location, nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+}
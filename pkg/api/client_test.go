@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/backup"
+	"github.com/georgemblack/web-api/pkg/build"
+	"github.com/georgemblack/web-api/pkg/client"
+	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/render"
+	"github.com/georgemblack/web-api/pkg/repo"
+	"github.com/georgemblack/web-api/pkg/testutil"
+	"github.com/georgemblack/web-api/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+// TestClient exercises the client against the router, so the client's requests and types can't drift from the API.
+func TestClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config, err := conf.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	server := httptest.NewServer(setupRouter(config, repo.NewMemoryService(), build.NewTrigger("", 0), nil))
+	defer server.Close()
+	ctx := context.Background()
+	api := client.New(server.URL, config.APIUsername, config.APIPassword, nil)
+
+	// ==================== Test case 1: Likes ====================
+	id, err := api.AddLike(ctx, testutil.NewLike())
+	if err != nil {
+		t.Fatalf("failed to add like; %s", err)
+	}
+	like, err := api.GetLike(ctx, id)
+	if err != nil || like.ID != id || like.Title != testutil.NewLike().Title {
+		t.Errorf("expected like %s, got %+v, %v", id, like, err)
+	}
+	likes, err := api.GetLikes(ctx, client.Page{Limit: 10})
+	if err != nil || len(likes.Likes) != 1 || likes.NextCursor != "" {
+		t.Errorf("expected 1 like, got %+v, %v", likes, err)
+	}
+	if err := api.DeleteLike(ctx, id); err != nil {
+		t.Errorf("failed to delete like; %s", err)
+	}
+	if _, err := api.GetLike(ctx, id); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if _, err := api.AddLike(ctx, types.Like{Title: "No URL"}); !errors.Is(err, client.ErrBadRequest) {
+		t.Errorf("expected bad request adding invalid like, got %v", err)
+	}
+
+	// ==================== Test case 2: Posts ====================
+	post := testutil.NewPost()
+	created, err := api.AddPost(ctx, post)
+	if err != nil {
+		t.Fatalf("failed to add post; %s", err)
+	}
+	if _, err := api.AddPost(ctx, post); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("expected conflict adding duplicate slug, got %v", err)
+	}
+	result, err := api.GetPost(ctx, created.ID)
+	if err != nil || result.Post.Title != post.Title || result.ETag == "" || result.Post.ContentHTML == "" {
+		t.Errorf("expected rendered post with etag, got %+v, %v", result, err)
+	}
+	if bySlug, err := api.GetPostBySlug(ctx, post.Slug, client.PostFilters{}); err != nil || bySlug.Post.ID != created.ID {
+		t.Errorf("expected post %s by slug, got %+v, %v", created.ID, bySlug, err)
+	}
+	posts, err := api.GetPosts(ctx, client.PostFilters{}, client.Page{})
+	if err != nil || len(posts.Posts) != 1 {
+		t.Errorf("expected 1 post, got %+v, %v", posts, err)
+	}
+
+	// ==================== Test case 3: Conditional writes ====================
+	result.Post.Title = "Updated"
+	updated, err := api.UpdatePost(ctx, result.Post, result.ETag)
+	if err != nil || updated.ETag == "" || updated.ETag == result.ETag {
+		t.Fatalf("expected new etag after update, got %+v, %v", updated, err)
+	}
+	if _, err := api.UpdatePost(ctx, result.Post, result.ETag); !errors.Is(err, client.ErrPreconditionFailed) {
+		t.Errorf("expected precondition failed with stale etag, got %v", err)
+	}
+	patched, err := api.PatchPost(ctx, created.ID, map[string]any{"title": "Patched", "tags": nil}, updated.ETag)
+	if err != nil || patched.Post.Title != "Patched" || patched.ID != created.ID || patched.ETag == "" {
+		t.Errorf("expected patched post, got %+v, %v", patched, err)
+	}
+
+	// ==================== Test case 4: Revisions ====================
+	revisions, err := api.GetRevisions(ctx, created.ID, client.Page{Limit: 1})
+	if err != nil || len(revisions.Revisions) != 1 || revisions.NextCursor == "" {
+		t.Fatalf("expected first page of revisions, got %+v, %v", revisions, err)
+	}
+	revisions, err = api.GetRevisions(ctx, created.ID, client.Page{Limit: 1, Cursor: revisions.NextCursor})
+	if err != nil || len(revisions.Revisions) != 1 || revisions.NextCursor != "" {
+		t.Fatalf("expected last page of revisions, got %+v, %v", revisions, err)
+	}
+	revision, err := api.GetRevision(ctx, created.ID, revisions.Revisions[0].ID)
+	if err != nil || revision.Revision.ID != revisions.Revisions[0].ID {
+		t.Errorf("expected revision, got %+v, %v", revision, err)
+	}
+	restored, err := api.RestoreRevision(ctx, created.ID, revision.Revision.ID, patched.ETag)
+	if err != nil || restored.Post.Title != revision.Revision.Post.Title {
+		t.Errorf("expected restored revision, got %+v, %v", restored, err)
+	}
+	if err := api.DeletePost(ctx, created.ID); err != nil {
+		t.Errorf("failed to delete post; %s", err)
+	}
+
+	// ==================== Test case 5: Public documents, builds and backups ====================
+	if spec, err := api.OpenAPI(ctx); err != nil || string(spec) != string(openAPISpec) {
+		t.Errorf("expected openapi document, got %v", err)
+	}
+	css, err := api.HighlightCSS(ctx, "")
+	if err != nil || !strings.Contains(string(css), ".chroma") {
+		t.Errorf("expected highlight css, got %v", err)
+	}
+	if _, err := api.HighlightCSS(ctx, "missing"); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("expected not found for unknown theme, got %v", err)
+	}
+	status, err := api.GetBuilds(ctx)
+	if err != nil || status.Enabled {
+		t.Errorf("expected builds disabled, got %+v, %v", status, err)
+	}
+	// Backups are disabled in this router
+	if _, err := api.Backup(ctx); !errors.Is(err, client.ErrServer) {
+		t.Errorf("expected server error with backups disabled, got %v", err)
+	}
+	if _, err := api.Restore(ctx, "", true); !errors.Is(err, client.ErrServer) {
+		t.Errorf("expected server error with backups disabled, got %v", err)
+	}
+
+	// ==================== Test case 6: Invalid credentials ====================
+	wrong := client.New(server.URL, config.APIUsername, "wrong", nil)
+	if _, err := wrong.GetPosts(ctx, client.PostFilters{}, client.Page{}); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("expected unauthorized, got %v", err)
+	}
+}
+
+// TestClientTypes verifies the client's copies of response types have the same JSON fields as the server's.
+func TestClientTypes(t *testing.T) {
+	pairs := []struct {
+		client any
+		server any
+	}{
+		{client.Heading{}, render.Heading{}},
+		{client.Removal{}, render.Removal{}},
+		{client.BuildStatus{}, build.Status{}},
+		{client.BackupResult{}, backup.Result{}},
+		{client.RestoreReport{}, backup.RestoreReport{}},
+	}
+	for _, pair := range pairs {
+		expected := jsonFields(reflect.TypeOf(pair.server), "")
+		actual := jsonFields(reflect.TypeOf(pair.client), "")
+		if !slices.Equal(actual, expected) {
+			t.Errorf("expected %T to have fields %v, got %v", pair.client, expected, actual)
+		}
+	}
+}
+
+// jsonFields returns the JSON paths of every field of a type, including those of nested structs.
+func jsonFields(t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return nil
+	}
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, prefix+name)
+		fields = append(fields, jsonFields(t.Field(i).Type, prefix+name+".")...)
+	}
+	slices.Sort(fields)
+	return fields
+}
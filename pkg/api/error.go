@@ -1,9 +1,12 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
+	"github.com/georgemblack/web-api/pkg/log"
+	"github.com/georgemblack/web-api/pkg/repo"
 	"github.com/georgemblack/web-api/pkg/types"
 	"github.com/gin-gonic/gin"
 )
@@ -17,6 +20,33 @@ func unauthorizedError(c *gin.Context) {
 	c.AbortWithStatusJSON(http.StatusUnauthorized, resp)
 }
 
+func notFoundError(c *gin.Context) {
+	resp := types.ErrorResponse{
+		Timestamp: time.Now().Format(time.RFC3339),
+		Message:   "Not found",
+		RequestID: c.GetString("requestId"),
+	}
+	c.AbortWithStatusJSON(http.StatusNotFound, resp)
+}
+
+func conflictError(c *gin.Context) {
+	resp := types.ErrorResponse{
+		Timestamp: time.Now().Format(time.RFC3339),
+		Message:   "Conflict",
+		RequestID: c.GetString("requestId"),
+	}
+	c.AbortWithStatusJSON(http.StatusConflict, resp)
+}
+
+func preconditionFailedError(c *gin.Context) {
+	resp := types.ErrorResponse{
+		Timestamp: time.Now().Format(time.RFC3339),
+		Message:   "Precondition failed",
+		RequestID: c.GetString("requestId"),
+	}
+	c.AbortWithStatusJSON(http.StatusPreconditionFailed, resp)
+}
+
 func internalServerError(c *gin.Context) {
 	resp := types.ErrorResponse{
 		Timestamp: time.Now().Format(time.RFC3339),
@@ -34,3 +64,25 @@ func invalidRequestError(c *gin.Context) {
 	}
 	c.AbortWithStatusJSON(http.StatusBadRequest, resp)
 }
+
+// repoError logs an error returned by the repo layer and responds with the matching status code.
+func repoError(c *gin.Context, err error, message string) {
+	err = types.WrapErr(err, message)
+	switch {
+	case errors.Is(err, repo.ErrNotFound):
+		log.Warn(c, err.Error())
+		notFoundError(c)
+	case errors.Is(err, repo.ErrConflict):
+		log.Warn(c, err.Error())
+		conflictError(c)
+	case errors.Is(err, repo.ErrPreconditionFailed):
+		log.Warn(c, err.Error())
+		preconditionFailedError(c)
+	case errors.Is(err, repo.ErrInvalid):
+		log.Warn(c, err.Error())
+		invalidRequestError(c)
+	default:
+		log.Error(c, err.Error())
+		internalServerError(c)
+	}
+}
@@ -2,16 +2,27 @@ package api
 
 import (
 	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/georgemblack/web-api/pkg/backup"
+	"github.com/georgemblack/web-api/pkg/build"
 	"github.com/georgemblack/web-api/pkg/conf"
 	"github.com/georgemblack/web-api/pkg/log"
+	"github.com/georgemblack/web-api/pkg/render"
 	"github.com/georgemblack/web-api/pkg/repo"
 	"github.com/georgemblack/web-api/pkg/types"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/pmezard/go-difflib/difflib"
 )
 
 func authHandler(config conf.Config) gin.HandlerFunc {
@@ -57,19 +68,122 @@ func authHandler(config conf.Config) gin.HandlerFunc {
 	}
 }
 
-func getLikesHandler(fs FirestoreService) gin.HandlerFunc {
+// openAPIHandler returns the OpenAPI document describing every route.
+func openAPIHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		likes, err := fs.GetLikes()
+		c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+	}
+}
+
+// highlightStyleHandler returns the stylesheet for the highlighting theme in the 'theme' query param,
+// matching the classes of highlighted code blocks in rendered posts.
+func highlightStyleHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		theme := c.DefaultQuery("theme", render.DefaultTheme)
+		css, err := render.HighlightCSS(theme)
+		if errors.Is(err, render.ErrUnknownTheme) {
+			log.Warn(c, err.Error())
+			notFoundError(c)
+			return
+		}
+		if err != nil {
+			log.Error(c, types.WrapErr(err, "failed to get highlight css").Error())
+			internalServerError(c)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=86400")
+		c.Data(http.StatusOK, "text/css; charset=utf-8", css)
+	}
+}
+
+// backupHandler backs up every post and like, and returns the name and manifest of the archive.
+func backupHandler(backups *backup.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if backups == nil {
+			log.Error(c, "backups are disabled")
+			internalServerError(c)
+			return
+		}
+
+		result, err := backups.Backup(c.Request.Context())
 		if err != nil {
-			log.Error(c, types.WrapErr(err, "failed to get likes").Error())
+			log.Error(c, types.WrapErr(err, "failed to back up").Error())
 			internalServerError(c)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"likes": likes})
+		c.JSON(http.StatusCreated, result)
 	}
 }
 
-func getLikeHandler(fs FirestoreService) gin.HandlerFunc {
+// restoreHandler restores the archive named in the request body, or the most recent archive if none is named.
+// With the 'dryRun' query param, the changes are reported without writing anything.
+func restoreHandler(backups *backup.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if backups == nil {
+			log.Error(c, "backups are disabled")
+			internalServerError(c)
+			return
+		}
+		dryRun, err := dryRun(c)
+		if err != nil {
+			log.Warn(c, err.Error())
+			invalidRequestError(c)
+			return
+		}
+		var req types.RestoreRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				log.Warn(c, types.WrapErr(err, "failed to bind json").Error())
+				invalidRequestError(c)
+				return
+			}
+		}
+
+		report, err := backups.Restore(c.Request.Context(), req.Name, dryRun)
+		switch {
+		case errors.Is(err, backup.ErrNotFound):
+			log.Warn(c, err.Error())
+			notFoundError(c)
+			return
+		case errors.Is(err, backup.ErrInvalidArchive):
+			log.Warn(c, err.Error())
+			invalidRequestError(c)
+			return
+		case err != nil:
+			log.Error(c, types.WrapErr(err, "failed to restore").Error())
+			internalServerError(c)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// getBuildsHandler returns the status of static site builds.
+func getBuildsHandler(builds *build.Trigger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, builds.Status())
+	}
+}
+
+func getLikesHandler(store repo.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := page(c)
+		if err != nil {
+			log.Warn(c, types.WrapErr(err, "invalid page").Error())
+			invalidRequestError(c)
+			return
+		}
+
+		likes, next, err := store.GetLikesPage(page)
+		if err != nil {
+			repoError(c, err, "failed to get likes")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"likes": likes, "nextCursor": next})
+	}
+}
+
+func getLikeHandler(store repo.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		if id == "" {
@@ -78,48 +192,145 @@ func getLikeHandler(fs FirestoreService) gin.HandlerFunc {
 			return
 		}
 
-		like, err := fs.GetLike(id)
+		like, err := store.GetLike(id)
 		if err != nil {
-			log.Error(c, types.WrapErr(err, "failed to get like").Error())
-			internalServerError(c)
+			repoError(c, err, "failed to get like")
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"like": like})
 	}
 }
 
-func getPostsHandler(fs FirestoreService) gin.HandlerFunc {
+func addLikeHandler(store repo.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		filters := repo.PostFilters{}
-		published := c.Query("published")
-		listed := c.Query("listed")
+		var like types.Like
+		if err := c.ShouldBindJSON(&like); err != nil {
+			log.Warn(c, types.WrapErr(err, "failed to bind json").Error())
+			invalidRequestError(c)
+			return
+		}
+		if err := like.Validate(); err != nil {
+			log.Warn(c, types.WrapErr(err, "invalid like").Error())
+			invalidRequestError(c)
+			return
+		}
+		if like.Timestamp.IsZero() {
+			like.Timestamp = time.Now()
+		}
 
-		t := true
-		f := false
-		if published == "true" {
-			filters.Published = &t
+		id, err := store.AddLike(like)
+		if err != nil {
+			repoError(c, err, "failed to add like")
+			return
 		}
-		if published == "false" {
-			filters.Published = &f
+		c.Header("Location", "/likes/"+id)
+		c.JSON(http.StatusCreated, gin.H{"id": id})
+	}
+}
+
+func deleteLikeHandler(store repo.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			log.Warn(c, "'id' param unexpectedly empty")
+			invalidRequestError(c)
+			return
+		}
+
+		if err := store.DeleteLike(id); err != nil {
+			repoError(c, err, "failed to delete like")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func getPostsHandler(store repo.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filters := postFilters(c)
+		page, err := page(c)
+		if err != nil {
+			log.Warn(c, types.WrapErr(err, "invalid page").Error())
+			invalidRequestError(c)
+			return
+		}
+
+		posts, next, err := store.GetPostsPage(filters, page)
+		if err != nil {
+			repoError(c, err, "failed to get posts")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"posts": posts, "nextCursor": next})
+	}
+}
+
+// getPostHandler returns a post, along with the table of contents of its content.
+func getPostHandler(store repo.Store, renderer *render.Renderer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			log.Warn(c, "'id' param unexpectedly empty")
+			invalidRequestError(c)
+			return
+		}
+
+		post, err := store.GetPost(id)
+		if err != nil {
+			repoError(c, err, "failed to get post")
+			return
+		}
+		c.Header("ETag", etag(post.Updated))
+		c.JSON(http.StatusOK, gin.H{"post": post, "toc": renderer.TOC(post.Content)})
+	}
+}
+
+// getPostBySlugHandler returns a post, along with the table of contents of its content.
+func getPostBySlugHandler(store repo.Store, renderer *render.Renderer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slug := c.Param("slug")
+		if slug == "" {
+			log.Warn(c, "'slug' param unexpectedly empty")
+			invalidRequestError(c)
+			return
 		}
-		if listed == "true" {
-			filters.Listed = &t
+
+		post, err := store.GetPostBySlug(slug, postFilters(c))
+		if err != nil {
+			repoError(c, err, "failed to get post by slug")
+			return
 		}
-		if listed == "false" {
-			filters.Listed = &f
+		c.Header("ETag", etag(post.Updated))
+		c.JSON(http.StatusOK, gin.H{"post": post, "toc": renderer.TOC(post.Content)})
+	}
+}
+
+func addPostHandler(store repo.Store, renderer *render.Renderer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var post types.Post
+		if err := c.ShouldBindJSON(&post); err != nil {
+			log.Warn(c, types.WrapErr(err, "failed to bind json").Error())
+			invalidRequestError(c)
+			return
 		}
 
-		posts, err := fs.GetPosts(filters)
+		post, report, err := renderer.Post(post)
 		if err != nil {
-			log.Error(c, types.WrapErr(err, "failed to get posts").Error())
+			log.Error(c, types.WrapErr(err, "failed to render post").Error())
 			internalServerError(c)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"posts": posts})
+
+		id, err := store.AddPost(post)
+		if err != nil {
+			repoError(c, err, "failed to add post")
+			return
+		}
+		c.Header("Location", "/posts/"+id)
+		c.JSON(http.StatusCreated, gin.H{"id": id, "sanitized": report})
 	}
 }
 
-func updatePostHandler(fs FirestoreService) gin.HandlerFunc {
+func updatePostHandler(store repo.Store, renderer *render.Renderer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		if id == "" {
@@ -135,12 +346,326 @@ func updatePostHandler(fs FirestoreService) gin.HandlerFunc {
 			return
 		}
 
+		post, report, err := renderer.Post(post)
+		if err != nil {
+			log.Error(c, types.WrapErr(err, "failed to render post").Error())
+			internalServerError(c)
+			return
+		}
+
+		// Only the 'If-Match' header makes the update conditional, not the body
+		updated, err := ifMatch(c)
+		if err != nil {
+			log.Warn(c, types.WrapErr(err, "invalid 'If-Match' header").Error())
+			invalidRequestError(c)
+			return
+		}
+
 		post.ID = id
-		if err := fs.UpdatePost(post); err != nil {
-			log.Error(c, types.WrapErr(err, "failed to update post").Error())
+		post.Updated = updated
+		updated, err = store.UpdatePost(post)
+		if err != nil {
+			repoError(c, err, "failed to update post")
+			return
+		}
+		c.Header("ETag", etag(updated))
+		c.JSON(http.StatusOK, gin.H{"sanitized": report})
+	}
+}
+
+// patchPostHandler applies a JSON merge patch to a post, updating only the fields present in the body.
+// Fields set to null are reset to their zero value. HTML fields are always rendered from the content,
+// so they are ignored in the patch, and re-rendered if the content is patched.
+func patchPostHandler(store repo.Store, renderer *render.Renderer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			log.Warn(c, "'id' param unexpectedly empty")
+			invalidRequestError(c)
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			log.Warn(c, types.WrapErr(err, "failed to read body").Error())
+			invalidRequestError(c)
+			return
+		}
+		var patch types.Post
+		fields, err := patchFields(body, &patch)
+		if err != nil {
+			log.Warn(c, types.WrapErr(err, "invalid patch").Error())
+			invalidRequestError(c)
+			return
+		}
+		fields = slices.DeleteFunc(fields, func(field string) bool {
+			return field == "contentHtml" || field == "contentHtmlPreview"
+		})
+		report := make(render.Report, 0)
+		if slices.Contains(fields, "content") {
+			patch, report, err = renderer.Post(patch)
+			if err != nil {
+				log.Error(c, types.WrapErr(err, "failed to render post").Error())
+				internalServerError(c)
+				return
+			}
+			fields = append(fields, "contentHtml", "contentHtmlPreview")
+		}
+
+		updated, err := ifMatch(c)
+		if err != nil {
+			log.Warn(c, types.WrapErr(err, "invalid 'If-Match' header").Error())
+			invalidRequestError(c)
+			return
+		}
+
+		patch.ID = id
+		patch.Updated = updated
+		post, err := store.PatchPost(patch, fields)
+		if err != nil {
+			repoError(c, err, "failed to patch post")
+			return
+		}
+		c.Header("ETag", etag(post.Updated))
+		c.JSON(http.StatusOK, gin.H{"post": post, "sanitized": report})
+	}
+}
+
+func deletePostHandler(store repo.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			log.Warn(c, "'id' param unexpectedly empty")
+			invalidRequestError(c)
+			return
+		}
+
+		if err := store.DeletePost(id); err != nil {
+			repoError(c, err, "failed to delete post")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func getRevisionsHandler(store repo.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			log.Warn(c, "'id' param unexpectedly empty")
+			invalidRequestError(c)
+			return
+		}
+
+		page, err := page(c)
+		if err != nil {
+			log.Warn(c, types.WrapErr(err, "invalid page").Error())
+			invalidRequestError(c)
+			return
+		}
+
+		revisions, next, err := store.GetRevisions(id, page)
+		if err != nil {
+			repoError(c, err, "failed to get revisions")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"revisions": revisions, "nextCursor": next})
+	}
+}
+
+// getRevisionHandler returns a revision of a post, along with a unified diff from the revision's
+// content to the current content.
+func getRevisionHandler(store repo.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		rev := c.Param("rev")
+		if id == "" || rev == "" {
+			log.Warn(c, "'id' or 'rev' param unexpectedly empty")
+			invalidRequestError(c)
+			return
+		}
+
+		revision, err := store.GetRevision(id, rev)
+		if err != nil {
+			repoError(c, err, "failed to get revision")
+			return
+		}
+		post, err := store.GetPost(id)
+		if err != nil {
+			repoError(c, err, "failed to get post")
+			return
+		}
+
+		diff, err := contentDiff(revision, post)
+		if err != nil {
+			log.Error(c, types.WrapErr(err, "failed to diff revision").Error())
 			internalServerError(c)
 			return
 		}
-		c.Status(http.StatusOK)
+		c.JSON(http.StatusOK, gin.H{"revision": revision, "diff": diff})
+	}
+}
+
+// restoreRevisionHandler replaces a post with one of its revisions. The current version of the
+// post is kept as a new revision, so a restore can itself be undone.
+func restoreRevisionHandler(store repo.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		rev := c.Param("rev")
+		if id == "" || rev == "" {
+			log.Warn(c, "'id' or 'rev' param unexpectedly empty")
+			invalidRequestError(c)
+			return
+		}
+
+		updated, err := ifMatch(c)
+		if err != nil {
+			log.Warn(c, types.WrapErr(err, "invalid 'If-Match' header").Error())
+			invalidRequestError(c)
+			return
+		}
+
+		revision, err := store.GetRevision(id, rev)
+		if err != nil {
+			repoError(c, err, "failed to get revision")
+			return
+		}
+		post := revision.Post
+		post.ID = id
+		post.Updated = updated
+		post.Updated, err = store.UpdatePost(post)
+		if err != nil {
+			repoError(c, err, "failed to restore revision")
+			return
+		}
+		c.Header("ETag", etag(post.Updated))
+		c.JSON(http.StatusOK, gin.H{"post": post})
+	}
+}
+
+// contentDiff returns a unified diff from a revision's content to a post's current content.
+func contentDiff(revision types.Revision, post types.Post) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(revision.Post.Content),
+		B:        difflib.SplitLines(post.Content),
+		FromFile: "revisions/" + revision.ID,
+		FromDate: revision.Post.Updated.Format(time.RFC3339),
+		ToFile:   "current",
+		ToDate:   post.Updated.Format(time.RFC3339),
+		Context:  3,
+	})
+}
+
+// postJSONFields holds the JSON names of every field of a post.
+var postJSONFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(types.Post{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields[name] = true
+	}
+	return fields
+}()
+
+// patchFields decodes a JSON merge patch into a post, returning the names of the fields it sets.
+// Returns an error if the patch names a field that posts don't have.
+func patchFields(body []byte, patch *types.Post) ([]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, types.WrapErr(err, "failed to parse json")
+	}
+	fields := make([]string, 0, len(raw))
+	for name := range raw {
+		if !postJSONFields[name] {
+			return nil, fmt.Errorf("unknown post field '%s'", name)
+		}
+		fields = append(fields, name)
+	}
+	slices.Sort(fields)
+
+	if err := json.Unmarshal(body, patch); err != nil {
+		return nil, types.WrapErr(err, "failed to parse json")
+	}
+	return fields, nil
+}
+
+// postFilters reads the optional 'published' and 'listed' query params into post filters.
+func postFilters(c *gin.Context) repo.PostFilters {
+	filters := repo.PostFilters{}
+	published := c.Query("published")
+	listed := c.Query("listed")
+
+	t := true
+	f := false
+	if published == "true" {
+		filters.Published = &t
+	}
+	if published == "false" {
+		filters.Published = &f
+	}
+	if listed == "true" {
+		filters.Listed = &t
+	}
+	if listed == "false" {
+		filters.Listed = &f
+	}
+	return filters
+}
+
+// dryRun reports whether the 'dryRun' query param is set.
+func dryRun(c *gin.Context) (bool, error) {
+	param := c.Query("dryRun")
+	if param == "" {
+		return false, nil
+	}
+	dryRun, err := strconv.ParseBool(param)
+	if err != nil {
+		return false, types.WrapErr(err, "failed to parse dryRun")
+	}
+	return dryRun, nil
+}
+
+// defaultPageLimit is the number of results in a page when no limit is requested.
+const defaultPageLimit = 20
+
+// maxPageLimit is the largest number of results that can be requested in a single page.
+const maxPageLimit = 100
+
+// page reads the optional 'limit' and 'cursor' query params.
+// When no limit is given, a page holds defaultPageLimit results.
+func page(c *gin.Context) (repo.Page, error) {
+	page := repo.Page{Limit: defaultPageLimit, Cursor: c.Query("cursor")}
+	limit := c.Query("limit")
+	if limit == "" {
+		return page, nil
+	}
+
+	n, err := strconv.Atoi(limit)
+	if err != nil {
+		return repo.Page{}, types.WrapErr(err, "failed to parse limit")
+	}
+	if n < 1 || n > maxPageLimit {
+		return repo.Page{}, fmt.Errorf("limit %d is not between 1 and %d", n, maxPageLimit)
+	}
+	page.Limit = n
+	return page, nil
+}
+
+// etag formats a post's update time as an entity tag.
+func etag(updated time.Time) string {
+	return fmt.Sprintf("\"%d\"", updated.UnixNano())
+}
+
+// ifMatch reads the update time a write is conditional on from the 'If-Match' header.
+// A zero time is returned if the header is absent or '*', meaning the write is unconditional.
+func ifMatch(c *gin.Context) (time.Time, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return time.Time{}, nil
+	}
+	nanos, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		return time.Time{}, fmt.Errorf("'%s' is not an entity tag issued by this server", header)
 	}
+	return time.Unix(0, nanos), nil
 }
@@ -1,14 +1,22 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/georgemblack/web-api/pkg/backup"
+	"github.com/georgemblack/web-api/pkg/build"
 	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/render"
+	"github.com/georgemblack/web-api/pkg/repo"
 	"github.com/georgemblack/web-api/pkg/testutil"
 	"github.com/georgemblack/web-api/pkg/types"
 	"github.com/gin-gonic/gin"
@@ -67,15 +75,60 @@ func TestAuthHandler(t *testing.T) {
 	}
 }
 
+func TestHighlightStyleHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := highlightStyleHandler()
+
+	// ==================== Test case 1: Default theme ====================
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/styles/highlight.css", nil)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Type") != "text/css; charset=utf-8" {
+		t.Errorf("expected css content type, got %s", w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), ".chroma") {
+		t.Errorf("expected chroma css, got %s", w.Body.String())
+	}
+
+	// ==================== Test case 2: Named theme ====================
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/styles/highlight.css?theme=monokai", nil)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+
+	// ==================== Test case 3: Unknown theme ====================
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/styles/highlight.css?theme=bogus", nil)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+}
+
 func TestGetLikesHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	ctrl := gomock.NewController(t)
-	fs := testutil.NewMockFirestoreService(ctrl)
-	handler := getLikesHandler(fs)
+	store := testutil.NewMockStore(ctrl)
+	handler := getLikesHandler(store)
 
-	// ==================== Test case 1: Valid request ====================
-	fs.EXPECT().GetLikes().Return(testutil.NewLikes(), nil)
+	// ==================== Test case 1: Valid request, default page size ====================
+	store.EXPECT().GetLikesPage(repo.Page{Limit: defaultPageLimit}).Return(testutil.NewLikes(), "", nil)
 
 	// Execute handler
 	w := httptest.NewRecorder()
@@ -89,7 +142,7 @@ func TestGetLikesHandler(t *testing.T) {
 	}
 
 	// ==================== Test case 2: Valid request, internal error ====================
-	fs.EXPECT().GetLikes().Return(testutil.NewLikes(), errors.New("ope"))
+	store.EXPECT().GetLikesPage(repo.Page{Limit: defaultPageLimit}).Return(testutil.NewLikes(), "", errors.New("ope"))
 
 	// Execute handler
 	w = httptest.NewRecorder()
@@ -101,18 +154,93 @@ func TestGetLikesHandler(t *testing.T) {
 	if w.Code != http.StatusInternalServerError {
 		t.Errorf("expected status code 500, got %d", w.Code)
 	}
+
+	// ==================== Test case 3: Paginated request ====================
+	store.EXPECT().GetLikesPage(repo.Page{Limit: 10, Cursor: "abc"}).Return(testutil.NewLikes(), "def", nil)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/likes?limit=10&cursor=abc", nil)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+	var resp struct {
+		NextCursor string `json:"nextCursor"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.NextCursor != "def" {
+		t.Errorf("expected next cursor 'def', got '%s'", resp.NextCursor)
+	}
+
+	// ==================== Test case 4: Invalid limits ====================
+	store.EXPECT().GetLikesPage(gomock.Any()).Times(0)
+
+	for _, limit := range []string{"0", "-1", "101", "bogus"} {
+		// Execute handler
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/likes?limit="+limit, nil)
+		handler(c)
+
+		// Check
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status code 400 for limit %s, got %d", limit, w.Code)
+		}
+	}
+}
+
+func TestGetPostsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	handler := getPostsHandler(store)
+
+	// ==================== Test case 1: Valid request with filters and page ====================
+	listed := true
+	filters := repo.PostFilters{Listed: &listed}
+	store.EXPECT().GetPostsPage(filters, repo.Page{Limit: 5}).Return([]types.Post{testutil.NewPost()}, "next", nil)
+
+	// Execute handler
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/posts?listed=true&limit=5", nil)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+
+	// ==================== Test case 2: Invalid cursor ====================
+	store.EXPECT().GetPostsPage(repo.PostFilters{}, repo.Page{Limit: defaultPageLimit, Cursor: "bogus"}).Return(nil, "", repo.ErrInvalid)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/posts?cursor=bogus", nil)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status code 400, got %d", w.Code)
+	}
 }
 
 func TestGetLikeHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	ctrl := gomock.NewController(t)
-	fs := testutil.NewMockFirestoreService(ctrl)
-	handler := getLikeHandler(fs)
+	store := testutil.NewMockStore(ctrl)
+	handler := getLikeHandler(store)
 
 	// ==================== Test case 1: Valid request ====================
 	like := testutil.NewLike()
-	fs.EXPECT().GetLike(like.ID).Return(like, nil)
+	store.EXPECT().GetLike(like.ID).Return(like, nil)
 
 	// Execute handler
 	w := httptest.NewRecorder()
@@ -127,11 +255,948 @@ func TestGetLikeHandler(t *testing.T) {
 
 	// ==================== Test case 2: Missing like ID ====================
 	like = testutil.NewLike()
-	fs.EXPECT().GetLike(like.ID).Times(0)
+	store.EXPECT().GetLike(like.ID).Times(0)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status code 400, got %d", w.Code)
+	}
+
+	// ==================== Test case 3: Like does not exist ====================
+	like = testutil.NewLike()
+	store.EXPECT().GetLike(like.ID).Return(types.Like{}, repo.ErrNotFound)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: like.ID})
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+}
+
+func TestGetPostHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	handler := getPostHandler(store, render.NewRenderer(conf.Config{}))
+
+	// ==================== Test case 1: Valid request ====================
+	post := testutil.NewPost()
+	store.EXPECT().GetPost(post.ID).Return(post, nil)
+
+	// Execute handler
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+	if w.Header().Get("ETag") != fmt.Sprintf("\"%d\"", post.Updated.UnixNano()) {
+		t.Errorf("expected etag for update time, got %s", w.Header().Get("ETag"))
+	}
+	var resp struct {
+		TOC []render.Heading `json:"toc"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response; %s", err)
+	}
+	if len(resp.TOC) != 1 || resp.TOC[0] != (render.Heading{Level: 1, Text: "Post", Anchor: "post"}) {
+		t.Errorf("expected toc with post heading, got %+v", resp.TOC)
+	}
+
+	// ==================== Test case 2: Missing post ID ====================
+	store.EXPECT().GetPost(gomock.Any()).Times(0)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status code 400, got %d", w.Code)
+	}
+
+	// ==================== Test case 3: Post does not exist ====================
+	store.EXPECT().GetPost(post.ID).Return(types.Post{}, types.WrapErr(repo.ErrNotFound, "failed to get post"))
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+}
+
+func TestAddPostHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	handler := addPostHandler(store, render.NewRenderer(conf.Config{}))
+
+	// ==================== Test case 1: Valid request ====================
+	post := testutil.NewPost()
+	body, _ := json.Marshal(post)
+	store.EXPECT().AddPost(gomock.Any()).Return(post.ID, nil)
+
+	// Execute handler
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/posts", bytes.NewReader(body))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status code 201, got %d", w.Code)
+	}
+	if w.Header().Get("Location") != "/posts/"+post.ID {
+		t.Errorf("expected location header /posts/%s, got %s", post.ID, w.Header().Get("Location"))
+	}
+
+	// ==================== Test case 2: Invalid body ====================
+	store.EXPECT().AddPost(gomock.Any()).Times(0)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/posts", bytes.NewReader([]byte("bogus")))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status code 400, got %d", w.Code)
+	}
+
+	// ==================== Test case 3: Slug already in use ====================
+	store.EXPECT().AddPost(gomock.Any()).Return("", types.WrapErr(repo.ErrConflict, "slug already in use"))
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/posts", bytes.NewReader(body))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected status code 409, got %d", w.Code)
+	}
+
+	// ==================== Test case 4: Valid request, internal error ====================
+	store.EXPECT().AddPost(gomock.Any()).Return("", errors.New("ope"))
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/posts", bytes.NewReader(body))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status code 500, got %d", w.Code)
+	}
+
+	// ==================== Test case 5: HTML is rendered from content ====================
+	unrendered := post
+	unrendered.Content = "Hello *world*"
+	unrendered.ContentHTML = "<p>stale</p>"
+	unrendered.ContentHTMLPreview = ""
+	body, _ = json.Marshal(unrendered)
+	store.EXPECT().AddPost(gomock.Any()).DoAndReturn(func(actual types.Post) (string, error) {
+		if actual.ContentHTML != "<p>Hello <em>world</em></p>\n" || actual.ContentHTMLPreview != actual.ContentHTML {
+			t.Errorf("expected html rendered from content, got %q and %q", actual.ContentHTML, actual.ContentHTMLPreview)
+		}
+		return post.ID, nil
+	})
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/posts", bytes.NewReader(body))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status code 201, got %d", w.Code)
+	}
+
+	// ==================== Test case 6: Stripped markup is reported ====================
+	unsafe := post
+	unsafe.Content = "Hello <script>alert(1)</script>"
+	body, _ = json.Marshal(unsafe)
+	store.EXPECT().AddPost(gomock.Any()).DoAndReturn(func(actual types.Post) (string, error) {
+		if strings.Contains(actual.ContentHTML, "script") {
+			t.Errorf("expected script to be stripped, got %q", actual.ContentHTML)
+		}
+		return post.ID, nil
+	})
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/posts", bytes.NewReader(body))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status code 201, got %d", w.Code)
+	}
+	var resp struct {
+		Sanitized render.Report `json:"sanitized"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response; %s", err)
+	}
+	if len(resp.Sanitized) != 1 || resp.Sanitized[0].Element != "script" {
+		t.Errorf("expected report of stripped script, got %+v", resp.Sanitized)
+	}
+}
+
+func TestUpdatePostHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	handler := updatePostHandler(store, render.NewRenderer(conf.Config{}))
+
+	post := testutil.NewPost()
+	body, _ := json.Marshal(post)
+	updated := post.Updated.Add(time.Second)
+
+	// ==================== Test case 1: Valid request, no 'If-Match' header ====================
+	store.EXPECT().UpdatePost(gomock.Any()).DoAndReturn(func(actual types.Post) (time.Time, error) {
+		if !actual.Updated.IsZero() {
+			t.Errorf("expected unconditional update, got update time %s", actual.Updated)
+		}
+		return updated, nil
+	})
+
+	// Execute handler
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	c.Request, _ = http.NewRequest("PUT", "/posts/"+post.ID, bytes.NewReader(body))
+	handler(c)
+
+	// Check
+	if c.Writer.Status() != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", c.Writer.Status())
+	}
+	if w.Header().Get("ETag") != fmt.Sprintf("\"%d\"", updated.UnixNano()) {
+		t.Errorf("expected etag for new update time, got %s", w.Header().Get("ETag"))
+	}
+
+	// ==================== Test case 2: Valid request, matching 'If-Match' header ====================
+	store.EXPECT().UpdatePost(gomock.Any()).DoAndReturn(func(actual types.Post) (time.Time, error) {
+		if !actual.Updated.Equal(time.Unix(0, post.Updated.UnixNano())) {
+			t.Errorf("expected update conditional on %s, got %s", post.Updated, actual.Updated)
+		}
+		return updated, nil
+	})
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	c.Request, _ = http.NewRequest("PUT", "/posts/"+post.ID, bytes.NewReader(body))
+	c.Request.Header.Set("If-Match", fmt.Sprintf("\"%d\"", post.Updated.UnixNano()))
+	handler(c)
+
+	// Check
+	if c.Writer.Status() != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", c.Writer.Status())
+	}
+
+	// ==================== Test case 3: Post changed since 'If-Match' version ====================
+	store.EXPECT().UpdatePost(gomock.Any()).Return(time.Time{}, types.WrapErr(repo.ErrPreconditionFailed, "post has changed"))
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	c.Request, _ = http.NewRequest("PUT", "/posts/"+post.ID, bytes.NewReader(body))
+	c.Request.Header.Set("If-Match", fmt.Sprintf("\"%d\"", post.Updated.UnixNano()))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected status code 412, got %d", w.Code)
+	}
+
+	// ==================== Test case 4: Malformed 'If-Match' header ====================
+	store.EXPECT().UpdatePost(gomock.Any()).Times(0)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	c.Request, _ = http.NewRequest("PUT", "/posts/"+post.ID, bytes.NewReader(body))
+	c.Request.Header.Set("If-Match", "W/\"bogus\"")
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status code 400, got %d", w.Code)
+	}
+
+	// ==================== Test case 5: Post does not exist ====================
+	store.EXPECT().UpdatePost(gomock.Any()).Return(time.Time{}, types.WrapErr(repo.ErrNotFound, "failed to update post"))
 
 	// Execute handler
 	w = httptest.NewRecorder()
 	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	c.Request, _ = http.NewRequest("PUT", "/posts/"+post.ID, bytes.NewReader(body))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+}
+
+func TestPatchPostHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	handler := patchPostHandler(store, render.NewRenderer(conf.Config{}))
+
+	post := testutil.NewPost()
+
+	// ==================== Test case 1: Valid request ====================
+	store.EXPECT().PatchPost(gomock.Any(), []string{"listed", "tags"}).DoAndReturn(func(patch types.Post, fields []string) (types.Post, error) {
+		if patch.ID != post.ID || patch.Listed || patch.Tags != nil {
+			t.Errorf("expected patch to unlist post and clear tags, got %+v", patch)
+		}
+		return post, nil
+	})
+
+	// Execute handler
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	c.Request, _ = http.NewRequest("PATCH", "/posts/"+post.ID, bytes.NewReader([]byte(`{"listed": false, "tags": null}`)))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+	if w.Header().Get("ETag") != fmt.Sprintf("\"%d\"", post.Updated.UnixNano()) {
+		t.Errorf("expected etag for update time, got %s", w.Header().Get("ETag"))
+	}
+
+	// ==================== Test case 2: Unknown field ====================
+	store.EXPECT().PatchPost(gomock.Any(), gomock.Any()).Times(0)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	c.Request, _ = http.NewRequest("PATCH", "/posts/"+post.ID, bytes.NewReader([]byte(`{"listed": false, "bogus": 1}`)))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status code 400, got %d", w.Code)
+	}
+
+	// ==================== Test case 3: Invalid body ====================
+	store.EXPECT().PatchPost(gomock.Any(), gomock.Any()).Times(0)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	c.Request, _ = http.NewRequest("PATCH", "/posts/"+post.ID, bytes.NewReader([]byte(`{"listed": "yes"}`)))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status code 400, got %d", w.Code)
+	}
+
+	// ==================== Test case 4: Read-only field ====================
+	store.EXPECT().PatchPost(gomock.Any(), []string{"id"}).Return(types.Post{}, types.WrapErr(repo.ErrInvalid, "field 'id' can't be patched"))
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	c.Request, _ = http.NewRequest("PATCH", "/posts/"+post.ID, bytes.NewReader([]byte(`{"id": "other"}`)))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status code 400, got %d", w.Code)
+	}
+
+	// ==================== Test case 5: Post changed since 'If-Match' version ====================
+	store.EXPECT().PatchPost(gomock.Any(), gomock.Any()).Return(types.Post{}, types.WrapErr(repo.ErrPreconditionFailed, "post has changed"))
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	c.Request, _ = http.NewRequest("PATCH", "/posts/"+post.ID, bytes.NewReader([]byte(`{"draft": true}`)))
+	c.Request.Header.Set("If-Match", fmt.Sprintf("\"%d\"", post.Updated.UnixNano()))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected status code 412, got %d", w.Code)
+	}
+
+	// ==================== Test case 6: Patched content is rendered ====================
+	store.EXPECT().PatchPost(gomock.Any(), []string{"content", "contentHtml", "contentHtmlPreview"}).DoAndReturn(func(patch types.Post, fields []string) (types.Post, error) {
+		if patch.ContentHTML != "<p>Hello</p>\n" {
+			t.Errorf("expected html rendered from content, got %q", patch.ContentHTML)
+		}
+		return post, nil
+	})
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	c.Request, _ = http.NewRequest("PATCH", "/posts/"+post.ID, bytes.NewReader([]byte(`{"content": "Hello", "contentHtml": "<p>stale</p>"}`)))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+}
+
+func TestGetRevisionsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	handler := getRevisionsHandler(store)
+
+	// ==================== Test case 1: Valid request ====================
+	post := testutil.NewPost()
+	revisions := []types.RevisionSummary{{ID: "rev", Created: time.Now(), Title: post.Title, Slug: post.Slug}}
+	store.EXPECT().GetRevisions(post.ID, repo.Page{Limit: defaultPageLimit}).Return(revisions, "", nil)
+
+	// Execute handler
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+
+	// ==================== Test case 2: Post does not exist ====================
+	store.EXPECT().GetRevisions(post.ID, repo.Page{Limit: defaultPageLimit}).Return(nil, "", types.WrapErr(repo.ErrNotFound, "failed to get post"))
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+}
+
+func TestGetRevisionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	handler := getRevisionHandler(store)
+
+	// ==================== Test case 1: Valid request ====================
+	post := testutil.NewPost()
+	post.Content = "# Post\n\nSecond version\n"
+	revision := types.Revision{ID: "rev", Created: time.Now(), Post: testutil.NewPost()}
+	revision.Post.Content = "# Post\n\nFirst version\n"
+	store.EXPECT().GetRevision(post.ID, revision.ID).Return(revision, nil)
+	store.EXPECT().GetPost(post.ID).Return(post, nil)
+
+	// Execute handler
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID}, gin.Param{Key: "rev", Value: revision.ID})
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+	var resp struct {
+		Diff string `json:"diff"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response; %s", err)
+	}
+	if !strings.Contains(resp.Diff, "-First version\n+Second version\n") {
+		t.Errorf("expected diff from first to second version, got %q", resp.Diff)
+	}
+
+	// ==================== Test case 2: Revision does not exist ====================
+	store.EXPECT().GetRevision(post.ID, "missing").Return(types.Revision{}, types.WrapErr(repo.ErrNotFound, "failed to get revision"))
+	store.EXPECT().GetPost(gomock.Any()).Times(0)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID}, gin.Param{Key: "rev", Value: "missing"})
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+}
+
+func TestRestoreRevisionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	handler := restoreRevisionHandler(store)
+
+	post := testutil.NewPost()
+	revision := types.Revision{ID: "rev", Created: time.Now(), Post: post}
+	revision.Post.Content = "# Old post"
+	updated := post.Updated.Add(time.Second)
+
+	// ==================== Test case 1: Valid request ====================
+	store.EXPECT().GetRevision(post.ID, revision.ID).Return(revision, nil)
+	store.EXPECT().UpdatePost(gomock.Any()).DoAndReturn(func(actual types.Post) (time.Time, error) {
+		if actual.Content != revision.Post.Content || !actual.Updated.IsZero() {
+			t.Errorf("expected unconditional update to revision content, got %+v", actual)
+		}
+		return updated, nil
+	})
+
+	// Execute handler
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID}, gin.Param{Key: "rev", Value: revision.ID})
+	c.Request, _ = http.NewRequest("POST", "/posts/"+post.ID+"/revisions/"+revision.ID+"/restore", nil)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+	if w.Header().Get("ETag") != fmt.Sprintf("\"%d\"", updated.UnixNano()) {
+		t.Errorf("expected etag for new update time, got %s", w.Header().Get("ETag"))
+	}
+
+	// ==================== Test case 2: Revision slug now in use ====================
+	store.EXPECT().GetRevision(post.ID, revision.ID).Return(revision, nil)
+	store.EXPECT().UpdatePost(gomock.Any()).Return(time.Time{}, types.WrapErr(repo.ErrConflict, "slug already in use"))
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID}, gin.Param{Key: "rev", Value: revision.ID})
+	c.Request, _ = http.NewRequest("POST", "/posts/"+post.ID+"/revisions/"+revision.ID+"/restore", nil)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected status code 409, got %d", w.Code)
+	}
+}
+
+func TestDeletePostHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	handler := deletePostHandler(store)
+
+	// ==================== Test case 1: Valid request ====================
+	post := testutil.NewPost()
+	store.EXPECT().DeletePost(post.ID).Return(nil)
+
+	// Execute handler
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	handler(c)
+
+	// Check
+	if c.Writer.Status() != http.StatusNoContent {
+		t.Errorf("expected status code 204, got %d", c.Writer.Status())
+	}
+
+	// ==================== Test case 2: Missing post ID ====================
+	store.EXPECT().DeletePost(gomock.Any()).Times(0)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status code 400, got %d", w.Code)
+	}
+
+	// ==================== Test case 3: Post does not exist ====================
+	store.EXPECT().DeletePost(post.ID).Return(repo.ErrNotFound)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+
+	// ==================== Test case 4: Conflicting request ====================
+	store.EXPECT().DeletePost(post.ID).Return(repo.ErrConflict)
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: post.ID})
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected status code 409, got %d", w.Code)
+	}
+}
+
+func TestAddLikeHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	handler := addLikeHandler(store)
+
+	// ==================== Test case 1: Valid request ====================
+	like := testutil.NewLike()
+	body, _ := json.Marshal(like)
+	store.EXPECT().AddLike(gomock.Any()).Return(like.ID, nil)
+
+	// Execute handler
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/likes", bytes.NewReader(body))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status code 201, got %d", w.Code)
+	}
+	if w.Header().Get("Location") != "/likes/"+like.ID {
+		t.Errorf("expected location header /likes/%s, got %s", like.ID, w.Header().Get("Location"))
+	}
+
+	// ==================== Test case 2: Missing timestamp defaults to now ====================
+	body = []byte(`{"title": "Like", "url": "https://google.com"}`)
+	store.EXPECT().AddLike(gomock.Any()).DoAndReturn(func(like types.Like) (string, error) {
+		if like.Timestamp.IsZero() {
+			t.Error("expected timestamp to be populated")
+		}
+		return like.ID, nil
+	})
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/likes", bytes.NewReader(body))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status code 201, got %d", w.Code)
+	}
+
+	// ==================== Test case 3: Invalid likes ====================
+	store.EXPECT().AddLike(gomock.Any()).Times(0)
+	invalid := []string{
+		`{"title": "", "url": "https://google.com"}`,
+		`{"title": "Like", "url": ""}`,
+		`{"title": "Like", "url": "/relative/path"}`,
+		`{"title": "Like", "url": "ftp://google.com"}`,
+		`{"title": "Like", "url": "https://"}`,
+	}
+
+	for _, body := range invalid {
+		// Execute handler
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/likes", bytes.NewReader([]byte(body)))
+		handler(c)
+
+		// Check
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status code 400 for %s, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestDeleteLikeHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	handler := deleteLikeHandler(store)
+
+	// ==================== Test case 1: Valid request ====================
+	like := testutil.NewLike()
+	store.EXPECT().DeleteLike(like.ID).Return(nil)
+
+	// Execute handler
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: like.ID})
+	handler(c)
+
+	// Check
+	if c.Writer.Status() != http.StatusNoContent {
+		t.Errorf("expected status code 204, got %d", c.Writer.Status())
+	}
+
+	// ==================== Test case 2: Valid request, internal error ====================
+	store.EXPECT().DeleteLike(like.ID).Return(errors.New("ope"))
+
+	// Execute handler
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: like.ID})
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status code 500, got %d", w.Code)
+	}
+}
+
+func TestGetPostBySlugHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config, err := conf.LoadConfig()
+	if err != nil {
+		t.Errorf("failed to load config: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	router := setupRouter(config, store, build.NewTrigger("", 0), nil)
+	token := testutil.GetJWT(config, router)
+
+	// ==================== Test case 1: Valid request, routed by slug ====================
+	post := testutil.NewPost()
+	published := true
+	store.EXPECT().GetPostBySlug(post.Slug, repo.PostFilters{Published: &published}).Return(post, nil)
+
+	// Execute request
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/posts/by-slug/"+post.Slug+"?published=true", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	router.ServeHTTP(w, req)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+
+	// ==================== Test case 2: Post does not exist or is hidden ====================
+	store.EXPECT().GetPostBySlug("bogus", repo.PostFilters{}).Return(types.Post{}, repo.ErrNotFound)
+
+	// Execute request
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/posts/by-slug/bogus", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	router.ServeHTTP(w, req)
+
+	// Check
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+}
+
+func TestBackupHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	blobs, err := backup.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create local store: %v", err)
+	}
+	handler := backupHandler(backup.NewService(store, blobs, render.NewRenderer(conf.Config{}), "test"))
+
+	// ==================== Test case 1: Valid request ====================
+	store.EXPECT().GetPosts(repo.PostFilters{}).Return([]types.Post{testutil.NewPost()}, nil)
+	store.EXPECT().GetLikes().Return(testutil.NewLikes(), nil)
+
+	// Execute request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/admin/backups", nil)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status code 201, got %d", w.Code)
+	}
+	var result backup.Result
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Errorf("failed to parse response: %v", err)
+	}
+	names, _ := blobs.List(context.Background(), backup.Prefix)
+	if len(names) != 1 || names[0] != result.Name {
+		t.Errorf("expected archive %s, got %v", result.Name, names)
+	}
+
+	// ==================== Test case 2: Store error ====================
+	store.EXPECT().GetPosts(repo.PostFilters{}).Return(nil, errors.New("ope"))
+
+	// Execute request
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/admin/backups", nil)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status code 500, got %d", w.Code)
+	}
+
+	// ==================== Test case 3: Backups disabled ====================
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/admin/backups", nil)
+	backupHandler(nil)(c)
+
+	// Check
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status code 500, got %d", w.Code)
+	}
+}
+
+func TestRestoreHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	blobs, err := backup.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create local store: %v", err)
+	}
+	backups := backup.NewService(store, blobs, render.NewRenderer(conf.Config{}), "test")
+	handler := restoreHandler(backups)
+
+	post := testutil.NewPost()
+	like := testutil.NewLike()
+	store.EXPECT().GetPosts(repo.PostFilters{}).Return([]types.Post{post}, nil)
+	store.EXPECT().GetLikes().Return([]types.Like{like}, nil)
+	result, err := backups.Backup(context.Background())
+	if err != nil {
+		t.Fatalf("failed to back up: %v", err)
+	}
+
+	// ==================== Test case 1: Dry run of the most recent archive ====================
+	store.EXPECT().GetPosts(repo.PostFilters{}).Return(nil, nil)
+	store.EXPECT().GetLikes().Return(nil, nil)
+
+	// Execute request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/admin/restore?dryRun=true", nil)
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+	var report backup.RestoreReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Errorf("failed to parse response: %v", err)
+	}
+	if report.Name != result.Name || !report.DryRun || len(report.Posts.Created) != 1 || len(report.Likes.Created) != 1 {
+		t.Errorf("expected dry run creating 1 post and 1 like, got %+v", report)
+	}
+
+	// ==================== Test case 2: Restore a named archive ====================
+	store.EXPECT().GetPosts(repo.PostFilters{}).Return([]types.Post{post}, nil)
+	store.EXPECT().GetLikes().Return(nil, nil)
+	store.EXPECT().PutPost(gomock.Any()).Return(nil)
+	store.EXPECT().PutLike(gomock.Any()).Return(nil)
+
+	// Execute request
+	body, _ := json.Marshal(types.RestoreRequest{Name: result.Name})
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/admin/restore", bytes.NewReader(body))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+	report = backup.RestoreReport{}
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Errorf("failed to parse response: %v", err)
+	}
+	if report.DryRun || len(report.Posts.Updated) != 1 || len(report.Likes.Created) != 1 {
+		t.Errorf("expected restore updating 1 post and creating 1 like, got %+v", report)
+	}
+
+	// ==================== Test case 3: Missing archive ====================
+	body, _ = json.Marshal(types.RestoreRequest{Name: backup.Prefix + "test/missing.tar.gz"})
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/admin/restore", bytes.NewReader(body))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+
+	// ==================== Test case 4: Invalid archive ====================
+	blobs.Put(context.Background(), backup.Prefix+"test/bogus.tar.gz", strings.NewReader("bogus"))
+	body, _ = json.Marshal(types.RestoreRequest{Name: backup.Prefix + "test/bogus.tar.gz"})
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/admin/restore", bytes.NewReader(body))
+	handler(c)
+
+	// Check
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status code 400, got %d", w.Code)
+	}
+
+	// ==================== Test case 5: Invalid dry run param ====================
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/admin/restore?dryRun=bogus", nil)
 	handler(c)
 
 	// Check
@@ -3,6 +3,7 @@ package api
 import (
 	"strings"
 
+	"github.com/georgemblack/web-api/pkg/build"
 	"github.com/georgemblack/web-api/pkg/conf"
 	"github.com/georgemblack/web-api/pkg/log"
 	"github.com/georgemblack/web-api/pkg/types"
@@ -22,8 +23,9 @@ func requestIDMiddleware() gin.HandlerFunc {
 func headerMiddleware(config conf.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", config.AllowedOriginHeader)
-		c.Header("Access-Control-Allow-Methods", "POST, PUT, GET, OPTIONS, DELETE")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Methods", "POST, PUT, PATCH, GET, OPTIONS, DELETE")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match")
+		c.Header("Access-Control-Expose-Headers", "ETag, Location")
 		c.Next()
 	}
 }
@@ -54,3 +56,17 @@ func validateJWTMiddleware(config conf.Config) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// Requests a build of the static site once a request has succeeded. Dry runs change nothing, so don't need a build.
+func buildMiddleware(builds *build.Trigger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if dryRun, _ := dryRun(c); dryRun {
+			return
+		}
+		if status := c.Writer.Status(); status >= 200 && status < 300 {
+			builds.Request()
+		}
+	}
+}
@@ -1,13 +1,20 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/georgemblack/web-api/pkg/build"
 	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/repo"
 	"github.com/georgemblack/web-api/pkg/testutil"
+	"github.com/georgemblack/web-api/pkg/types"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/mock/gomock"
 )
@@ -20,8 +27,8 @@ func TestValidateJWTMiddleware(t *testing.T) {
 	}
 
 	ctrl := gomock.NewController(t)
-	firestore := testutil.NewMockFirestoreService(ctrl)
-	router := setupRouter(config, firestore)
+	store := testutil.NewMockStore(ctrl)
+	router := setupRouter(config, store, build.NewTrigger("", 0), nil)
 	middleware := validateJWTMiddleware(config)
 
 	// ==================== Test case 1: Valid token ====================
@@ -71,3 +78,79 @@ func TestValidateJWTMiddleware(t *testing.T) {
 		t.Errorf("expected status code 401, got %d", w.Code)
 	}
 }
+
+func TestBuildMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config, err := conf.LoadConfig()
+	if err != nil {
+		t.Errorf("failed to load config: %v", err)
+	}
+
+	// Stand-in for the build service
+	var builds atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		builds.Add(1)
+	}))
+	defer server.Close()
+	trigger := build.NewTrigger(server.URL, 10*time.Millisecond)
+	defer trigger.Close()
+
+	ctrl := gomock.NewController(t)
+	store := testutil.NewMockStore(ctrl)
+	router := setupRouter(config, store, trigger, nil)
+	token := testutil.GetJWT(config, router)
+
+	request := func(method string, path string, body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		router.ServeHTTP(w, req)
+		return w
+	}
+	status := func() build.Status {
+		var status build.Status
+		w := request("GET", "/builds", nil)
+		if w.Code != http.StatusOK {
+			t.Errorf("expected status code 200, got %d", w.Code)
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+			t.Errorf("failed to parse build status: %v", err)
+		}
+		return status
+	}
+
+	// ==================== Test case 1: Reads don't trigger builds ====================
+	like := testutil.NewLike()
+	store.EXPECT().GetLike(like.ID).Return(like, nil)
+	request("GET", "/likes/"+like.ID, nil)
+	if actual := status(); !actual.Enabled || actual.Pending {
+		t.Errorf("expected enabled trigger with nothing pending, got %+v", actual)
+	}
+
+	// ==================== Test case 2: Failed mutations don't trigger builds ====================
+	store.EXPECT().DeleteLike("bogus").Return(repo.ErrNotFound)
+	request("DELETE", "/likes/bogus", nil)
+	if actual := status(); actual.Pending {
+		t.Errorf("expected nothing pending, got %+v", actual)
+	}
+
+	// ==================== Test case 3: Successful mutations trigger a build ====================
+	store.EXPECT().DeleteLike(like.ID).Return(nil)
+	store.EXPECT().AddLike(gomock.Any()).Return(like.ID, nil)
+	request("DELETE", "/likes/"+like.ID, nil)
+	body, _ := json.Marshal(types.Like{Title: like.Title, URL: like.URL})
+	request("POST", "/likes", body)
+
+	deadline := time.Now().Add(5 * time.Second)
+	actual := status()
+	for actual.LastBuild == nil && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		actual = status()
+	}
+	if actual.LastBuild == nil || !actual.LastBuild.Succeeded {
+		t.Errorf("expected successful build, got %+v", actual)
+	}
+	if builds.Load() != 1 {
+		t.Errorf("expected 1 call to build service, got %d", builds.Load())
+	}
+}
@@ -0,0 +1,8 @@
+package api
+
+import _ "embed"
+
+// openAPISpec is the OpenAPI document describing the API. Every route registered in setupRouter must be described in it.
+//
+//go:embed openapi.json
+var openAPISpec []byte
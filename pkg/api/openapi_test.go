@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/georgemblack/web-api/pkg/build"
+	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/repo"
+	"github.com/gin-gonic/gin"
+)
+
+// ginParam matches path params in gin routes, such as ':id', to convert them to OpenAPI params, such as '{id}'.
+var ginParam = regexp.MustCompile(`:(\w+)`)
+
+func TestOpenAPISpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config, err := conf.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	router := setupRouter(config, repo.NewMemoryService(), build.NewTrigger("", 0), nil)
+
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("failed to parse spec; %s", err)
+	}
+	if spec.OpenAPI != "3.1.0" {
+		t.Errorf("expected openapi 3.1.0, got '%s'", spec.OpenAPI)
+	}
+
+	// ==================== Test case 1: Every route is described ====================
+	routes := make(map[string]bool)
+	for _, route := range router.Routes() {
+		// CORS preflight requests are answered for every path
+		if route.Method == http.MethodOptions {
+			continue
+		}
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+		method := strings.ToLower(route.Method)
+		routes[method+" "+path] = true
+		if _, ok := spec.Paths[path][method]; !ok {
+			t.Errorf("route %s %s is not described in openapi.json", route.Method, route.Path)
+		}
+	}
+
+	// ==================== Test case 2: Every operation described is a route ====================
+	for path, item := range spec.Paths {
+		for method := range item {
+			if method == "parameters" {
+				continue
+			}
+			if !routes[method+" "+path] {
+				t.Errorf("operation %s %s in openapi.json is not a route", strings.ToUpper(method), path)
+			}
+		}
+	}
+
+	// ==================== Test case 3: Every reference resolves ====================
+	var doc map[string]any
+	_ = json.Unmarshal(openAPISpec, &doc)
+	for _, ref := range regexp.MustCompile(`"\$ref": "#/([^"]+)"`).FindAllStringSubmatch(string(openAPISpec), -1) {
+		var node any = doc
+		for _, key := range strings.Split(ref[1], "/") {
+			m, _ := node.(map[string]any)
+			node = m[key]
+		}
+		if node == nil {
+			t.Errorf("reference '#/%s' does not resolve", ref[1])
+		}
+	}
+
+	// ==================== Test case 4: Served without a token ====================
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/openapi.json", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", w.Code)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") || w.Body.String() != string(openAPISpec) {
+		t.Errorf("expected spec as json, got '%s'", w.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,214 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// FormatVersion is the version of the archive format written by this package.
+// Archives with a newer version can't be read.
+const FormatVersion = 1
+
+const (
+	manifestFile = "manifest.json"
+	postsFile    = "posts.ndjson"
+	likesFile    = "likes.ndjson"
+)
+
+// ErrInvalidArchive is returned when an archive can't be read, or its contents don't match its manifest.
+var ErrInvalidArchive = errors.New("invalid archive")
+
+// Manifest describes the contents of an archive.
+type Manifest struct {
+	Version int            `json:"version"`
+	Created time.Time      `json:"created"`
+	Files   []ManifestFile `json:"files"`
+}
+
+// ManifestFile describes a single file within an archive.
+type ManifestFile struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	SHA256  string `json:"sha256"`
+}
+
+// Archive holds the posts and likes read from an archive.
+type Archive struct {
+	Manifest Manifest
+	Posts    []types.Post
+	Likes    []types.Like
+}
+
+// writeArchive writes posts and likes as a gzip-compressed tar archive, holding a manifest and a
+// file of newline-delimited JSON for each.
+func writeArchive(w io.Writer, created time.Time, posts []types.Post, likes []types.Like) (Manifest, error) {
+	postsData, err := encodeRecords(posts)
+	if err != nil {
+		return Manifest{}, types.WrapErr(err, "failed to encode posts")
+	}
+	likesData, err := encodeRecords(likes)
+	if err != nil {
+		return Manifest{}, types.WrapErr(err, "failed to encode likes")
+	}
+
+	manifest := Manifest{
+		Version: FormatVersion,
+		Created: created.UTC(),
+		Files: []ManifestFile{
+			{Name: postsFile, Records: len(posts), SHA256: checksum(postsData)},
+			{Name: likesFile, Records: len(likes), SHA256: checksum(likesData)},
+		},
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, types.WrapErr(err, "failed to encode manifest")
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{manifestFile, manifestData},
+		{postsFile, postsData},
+		{likesFile, likesData},
+	}
+	for _, file := range files {
+		header := &tar.Header{
+			Name:    file.name,
+			Mode:    0o644,
+			Size:    int64(len(file.data)),
+			ModTime: manifest.Created,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return Manifest{}, types.WrapErr(err, "failed to write archive")
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			return Manifest{}, types.WrapErr(err, "failed to write archive")
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return Manifest{}, types.WrapErr(err, "failed to write archive")
+	}
+	if err := gz.Close(); err != nil {
+		return Manifest{}, types.WrapErr(err, "failed to write archive")
+	}
+	return manifest, nil
+}
+
+// ReadArchive reads an archive written by a backup, verifying each file against the checksums and
+// record counts in its manifest. Returns ErrInvalidArchive if the archive doesn't match.
+func ReadArchive(r io.Reader) (Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Archive{}, fmt.Errorf("failed to decompress archive; %s; %w", err, ErrInvalidArchive)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Archive{}, fmt.Errorf("failed to read archive; %s; %w", err, ErrInvalidArchive)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return Archive{}, fmt.Errorf("failed to read '%s'; %s; %w", header.Name, err, ErrInvalidArchive)
+		}
+		files[header.Name] = data
+	}
+
+	var archive Archive
+	data, ok := files[manifestFile]
+	if !ok {
+		return Archive{}, fmt.Errorf("archive has no manifest; %w", ErrInvalidArchive)
+	}
+	if err := json.Unmarshal(data, &archive.Manifest); err != nil {
+		return Archive{}, fmt.Errorf("failed to parse manifest; %s; %w", err, ErrInvalidArchive)
+	}
+	if archive.Manifest.Version < 1 || archive.Manifest.Version > FormatVersion {
+		return Archive{}, fmt.Errorf("unsupported archive version %d; %w", archive.Manifest.Version, ErrInvalidArchive)
+	}
+
+	for _, file := range archive.Manifest.Files {
+		data, ok := files[file.Name]
+		if !ok {
+			return Archive{}, fmt.Errorf("archive is missing '%s'; %w", file.Name, ErrInvalidArchive)
+		}
+		if checksum(data) != file.SHA256 {
+			return Archive{}, fmt.Errorf("checksum of '%s' does not match manifest; %w", file.Name, ErrInvalidArchive)
+		}
+
+		var records int
+		switch file.Name {
+		case postsFile:
+			archive.Posts, err = decodeRecords[types.Post](data)
+			records = len(archive.Posts)
+		case likesFile:
+			archive.Likes, err = decodeRecords[types.Like](data)
+			records = len(archive.Likes)
+		default:
+			continue
+		}
+		if err != nil {
+			return Archive{}, fmt.Errorf("failed to parse '%s'; %s; %w", file.Name, err, ErrInvalidArchive)
+		}
+		if records != file.Records {
+			return Archive{}, fmt.Errorf("'%s' holds %d records, expected %d; %w", file.Name, records, file.Records, ErrInvalidArchive)
+		}
+	}
+	return archive, nil
+}
+
+// encodeRecords encodes each record as a line of JSON.
+func encodeRecords[T any](records []T) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeRecords decodes a line of JSON per record, skipping blank lines.
+func decodeRecords[T any](data []byte) ([]T, error) {
+	records := []T{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var record T
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
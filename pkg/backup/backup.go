@@ -0,0 +1,97 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/render"
+	"github.com/georgemblack/web-api/pkg/repo"
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// Prefix is the prefix of the names of all backup archives. Archives are kept under a namespace within it.
+const Prefix = "backups/"
+
+// Service backs up every post and like in a store to a blob store.
+type Service struct {
+	store    repo.Store
+	blobs    BlobStore
+	renderer *render.Renderer
+	prefix   string
+}
+
+// Result describes a completed backup.
+type Result struct {
+	Name     string   `json:"name"`
+	Manifest Manifest `json:"manifest"`
+}
+
+// NewService creates a service keeping archives under the namespace, such as one from Namespace.
+// Restored posts are rendered with the renderer, as on every other write.
+func NewService(store repo.Store, blobs BlobStore, renderer *render.Renderer, namespace string) *Service {
+	return &Service{store: store, blobs: blobs, renderer: renderer, prefix: Prefix + namespace + "/"}
+}
+
+// Namespace names the configured store, so environments sharing a backup bucket never restore each other's archives.
+// Firestore stores are named by their database, and other stores by their backend.
+func Namespace(config conf.Config) string {
+	switch config.StorageBackend {
+	case "", "firestore":
+		return strings.Trim(config.FirestoreDatabaseName, "()")
+	}
+	return config.StorageBackend
+}
+
+// Backup writes every post and like to a new archive in the blob store.
+// Archives are named by their creation time, so they sort from oldest to newest.
+func (s *Service) Backup(ctx context.Context) (Result, error) {
+	var buf bytes.Buffer
+	manifest, err := WriteArchive(&buf, s.store)
+	if err != nil {
+		return Result{}, err
+	}
+	name := fmt.Sprintf("%s%s.tar.gz", s.prefix, manifest.Created.Format("20060102T150405.000000000Z"))
+	if err := s.blobs.Put(ctx, name, &buf); err != nil {
+		return Result{}, types.WrapErr(err, "failed to upload archive")
+	}
+	return Result{Name: name, Manifest: manifest}, nil
+}
+
+// WriteArchive writes every post, including drafts and unlisted posts, and every like in a store to an archive.
+func WriteArchive(w io.Writer, store repo.Store) (Manifest, error) {
+	posts, err := store.GetPosts(repo.PostFilters{})
+	if err != nil {
+		return Manifest{}, types.WrapErr(err, "failed to get posts")
+	}
+	likes, err := store.GetLikes()
+	if err != nil {
+		return Manifest{}, types.WrapErr(err, "failed to get likes")
+	}
+	return writeArchive(w, time.Now(), posts, likes)
+}
+
+// Schedule runs a backup every interval until the context is cancelled.
+// Failed backups are logged, and tried again at the next interval.
+func (s *Service) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.Backup(ctx)
+			if err != nil {
+				slog.Error(types.WrapErr(err, "scheduled backup failed").Error())
+				continue
+			}
+			slog.Info(fmt.Sprintf("scheduled backup written to '%s'", result.Name))
+		}
+	}
+}
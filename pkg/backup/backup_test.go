@@ -0,0 +1,194 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/render"
+	"github.com/georgemblack/web-api/pkg/repo"
+	"github.com/georgemblack/web-api/pkg/testutil"
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// newTestService creates a backup service for an in-memory store holding a draft and a published post,
+// and two likes, writing archives to a temporary directory.
+func newTestService(t *testing.T) (*Service, *Local, repo.Store) {
+	store := repo.NewMemoryService()
+	for _, draft := range []bool{true, false} {
+		post := testutil.NewPost()
+		post.Draft = draft
+		post.Slug = fmt.Sprintf("post-%t", draft)
+		if _, err := store.AddPost(post); err != nil {
+			t.Fatalf("failed to add post; %s", err)
+		}
+	}
+	for _, like := range []types.Like{testutil.NewLike(), testutil.NewLike()} {
+		if _, err := store.AddLike(like); err != nil {
+			t.Fatalf("failed to add like; %s", err)
+		}
+	}
+
+	blobs, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create local store; %s", err)
+	}
+	return NewService(store, blobs, render.NewRenderer(conf.Config{}), "test"), blobs, store
+}
+
+// readBlob reads and decompresses the named archive, returning each file within it.
+func readBlob(t *testing.T, blobs BlobStore, name string) map[string][]byte {
+	r, err := blobs.Get(context.Background(), name)
+	if err != nil {
+		t.Fatalf("failed to get archive; %s", err)
+	}
+	defer r.Close()
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatalf("failed to decompress archive; %s", err)
+	}
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files
+		}
+		if err != nil {
+			t.Fatalf("failed to read archive; %s", err)
+		}
+		files[header.Name], _ = io.ReadAll(tr)
+	}
+}
+
+// packFiles writes files to a gzip-compressed tar archive.
+func packFiles(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatalf("failed to write archive; %s", err)
+		}
+		tw.Write(data)
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestBackup(t *testing.T) {
+	ctx := context.Background()
+	service, blobs, store := newTestService(t)
+
+	// ==================== Test case 1: Every post and like is backed up ====================
+	result, err := service.Backup(ctx)
+	if err != nil {
+		t.Fatalf("failed to back up; %s", err)
+	}
+	if !strings.HasPrefix(result.Name, Prefix+"test/") || !strings.HasSuffix(result.Name, ".tar.gz") {
+		t.Errorf("expected archive name in '%stest/' ending in '.tar.gz', got '%s'", Prefix, result.Name)
+	}
+	if result.Manifest.Version != FormatVersion {
+		t.Errorf("expected version %d, got %d", FormatVersion, result.Manifest.Version)
+	}
+	if len(result.Manifest.Files) != 2 || result.Manifest.Files[0].Records != 2 || result.Manifest.Files[1].Records != 2 {
+		t.Errorf("expected manifest of 2 posts and 2 likes, got %+v", result.Manifest.Files)
+	}
+
+	// ==================== Test case 2: Archives can be read back ====================
+	r, err := blobs.Get(ctx, result.Name)
+	if err != nil {
+		t.Fatalf("failed to get archive; %s", err)
+	}
+	archive, err := ReadArchive(r)
+	r.Close()
+	if err != nil {
+		t.Fatalf("failed to read archive; %s", err)
+	}
+	posts, _ := store.GetPosts(repo.PostFilters{})
+	likes, _ := store.GetLikes()
+	if len(archive.Posts) != len(posts) || len(archive.Likes) != len(likes) {
+		t.Fatalf("expected %d posts and %d likes, got %d and %d", len(posts), len(likes), len(archive.Posts), len(archive.Likes))
+	}
+	for i, post := range posts {
+		actual := archive.Posts[i]
+		if actual.ID != post.ID || actual.Title != post.Title || actual.Content != post.Content || !actual.Published.Equal(post.Published) {
+			t.Errorf("expected post %+v, got %+v", post, actual)
+		}
+	}
+	for i, like := range likes {
+		if archive.Likes[i].ID != like.ID || !archive.Likes[i].Timestamp.Equal(like.Timestamp) {
+			t.Errorf("expected like %+v, got %+v", like, archive.Likes[i])
+		}
+	}
+
+	// ==================== Test case 3: Archives sort from oldest to newest ====================
+	time.Sleep(time.Millisecond)
+	second, err := service.Backup(ctx)
+	if err != nil {
+		t.Fatalf("failed to back up; %s", err)
+	}
+	names, _ := blobs.List(ctx, Prefix)
+	if len(names) != 2 || names[0] != result.Name || names[1] != second.Name {
+		t.Errorf("expected archives [%s %s], got %v", result.Name, second.Name, names)
+	}
+}
+
+func TestReadArchive(t *testing.T) {
+	service, blobs, _ := newTestService(t)
+	result, err := service.Backup(context.Background())
+	if err != nil {
+		t.Fatalf("failed to back up; %s", err)
+	}
+	valid := readBlob(t, blobs, result.Name)
+
+	// copyFiles copies the valid archive's files, applying a change
+	copyFiles := func(change func(files map[string][]byte)) map[string][]byte {
+		files := make(map[string][]byte)
+		for name, data := range valid {
+			files[name] = append([]byte{}, data...)
+		}
+		change(files)
+		return files
+	}
+
+	cases := map[string]map[string][]byte{
+		"missing manifest": copyFiles(func(files map[string][]byte) { delete(files, manifestFile) }),
+		"missing file":     copyFiles(func(files map[string][]byte) { delete(files, likesFile) }),
+		"modified file": copyFiles(func(files map[string][]byte) {
+			files[postsFile] = bytes.Replace(files[postsFile], []byte("test"), []byte("TEST"), 1)
+		}),
+		"wrong record count": copyFiles(func(files map[string][]byte) {
+			files[manifestFile] = bytes.Replace(files[manifestFile], []byte(`"records": 2`), []byte(`"records": 3`), 1)
+		}),
+		"newer version": copyFiles(func(files map[string][]byte) {
+			files[manifestFile] = bytes.Replace(files[manifestFile], []byte(`"version": 1`), []byte(`"version": 2`), 1)
+		}),
+	}
+	for name, files := range cases {
+		_, err := ReadArchive(bytes.NewReader(packFiles(t, files)))
+		if !errors.Is(err, ErrInvalidArchive) {
+			t.Errorf("expected invalid archive for %s, got %v", name, err)
+		}
+	}
+
+	// Files not in the manifest are ignored, and the valid archive can still be read
+	files := copyFiles(func(files map[string][]byte) { files["extra.txt"] = []byte("extra") })
+	if _, err := ReadArchive(bytes.NewReader(packFiles(t, files))); err != nil {
+		t.Errorf("expected archive with extra file to be read, got %v", err)
+	}
+
+	// Data that isn't an archive
+	if _, err := ReadArchive(strings.NewReader("bogus")); !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("expected invalid archive for bogus data, got %v", err)
+	}
+}
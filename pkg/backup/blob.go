@@ -0,0 +1,33 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+)
+
+// ErrNotFound is returned when a blob does not exist.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore is where backup archives are kept.
+type BlobStore interface {
+	// Put writes a blob, replacing any existing blob with the same name.
+	Put(ctx context.Context, name string, r io.Reader) error
+	// Get opens a blob for reading. Returns ErrNotFound if it does not exist.
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	// List returns the names of blobs starting with the prefix, in lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+	Close() error
+}
+
+// NewBlobStore creates the blob store for the configured backup bucket. Buckets named with a 'gs://' prefix
+// are stored in Google Cloud Storage, and anything else is treated as a local directory.
+func NewBlobStore(ctx context.Context, config conf.Config) (BlobStore, error) {
+	if bucket, ok := strings.CutPrefix(config.BackupBucketName, "gs://"); ok {
+		return NewGCSStore(ctx, bucket)
+	}
+	return NewLocalStore(config.BackupBucketName)
+}
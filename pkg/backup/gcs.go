@@ -0,0 +1,74 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"github.com/georgemblack/web-api/pkg/types"
+	"google.golang.org/api/iterator"
+)
+
+// GCS stores blobs as objects in a Google Cloud Storage bucket.
+type GCS struct {
+	client *storage.Client
+	bucket string
+}
+
+// NewGCSStore creates a blob store for the named bucket, using application default credentials.
+func NewGCSStore(ctx context.Context, bucket string) (*GCS, error) {
+	if bucket == "" {
+		return &GCS{}, errors.New("backup bucket name is empty")
+	}
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return &GCS{}, types.WrapErr(err, "failed to create storage client")
+	}
+	return &GCS{client: client, bucket: bucket}, nil
+}
+
+func (g *GCS) Put(ctx context.Context, name string, r io.Reader) error {
+	w := g.client.Bucket(g.bucket).Object(name).NewWriter(ctx)
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return types.WrapErr(err, "failed to write object")
+	}
+	// The object is only created once the writer is closed
+	if err := w.Close(); err != nil {
+		return types.WrapErr(err, "failed to write object")
+	}
+	return nil
+}
+
+func (g *GCS) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	r, err := g.client.Bucket(g.bucket).Object(name).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, fmt.Errorf("failed to get object '%s'; %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return nil, types.WrapErr(err, "failed to open object")
+	}
+	return r, nil
+}
+
+// List returns the names of objects starting with the prefix. Objects are listed in lexical order by GCS.
+func (g *GCS) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	it := g.client.Bucket(g.bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return names, nil
+		}
+		if err != nil {
+			return nil, types.WrapErr(err, "failed to list objects")
+		}
+		names = append(names, attrs.Name)
+	}
+}
+
+func (g *GCS) Close() error {
+	return g.client.Close()
+}
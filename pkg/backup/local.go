@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// Local stores blobs as files in a directory, for self-hosting and tests.
+type Local struct {
+	dir string
+}
+
+// NewLocalStore creates a blob store in the given directory, creating it if needed.
+func NewLocalStore(dir string) (*Local, error) {
+	if dir == "" {
+		return &Local{}, errors.New("backup directory is empty")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return &Local{}, types.WrapErr(err, "failed to create backup directory")
+	}
+	return &Local{dir: dir}, nil
+}
+
+// Put writes a blob to a temporary file, then renames it into place, so a partially written blob is never visible.
+func (l *Local) Put(ctx context.Context, name string, r io.Reader) error {
+	path, err := l.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return types.WrapErr(err, "failed to create blob directory")
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return types.WrapErr(err, "failed to create blob")
+	}
+	defer os.Remove(file.Name())
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return types.WrapErr(err, "failed to write blob")
+	}
+	if err := file.Close(); err != nil {
+		return types.WrapErr(err, "failed to write blob")
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return types.WrapErr(err, "failed to write blob")
+	}
+	return nil
+}
+
+func (l *Local) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	path, err := l.path(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to get blob '%s'; %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return nil, types.WrapErr(err, "failed to open blob")
+	}
+	return file, nil
+}
+
+func (l *Local) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(l.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(l.dir, path)
+		if err != nil {
+			return err
+		}
+		if name := filepath.ToSlash(rel); strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, types.WrapErr(err, "failed to list blobs")
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (l *Local) Close() error {
+	return nil
+}
+
+// path returns the file path of a blob, rejecting names that would escape the directory.
+func (l *Local) path(name string) (string, error) {
+	path := filepath.FromSlash(name)
+	if name == "" || !filepath.IsLocal(path) {
+		return "", fmt.Errorf("invalid blob name '%s'", name)
+	}
+	return filepath.Join(l.dir, path), nil
+}
@@ -0,0 +1,77 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	blobs, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create local store; %s", err)
+	}
+
+	// ==================== Test case 1: Put and get a blob ====================
+	if err := blobs.Put(ctx, "backups/a.tar.gz", strings.NewReader("first")); err != nil {
+		t.Fatalf("failed to put blob; %s", err)
+	}
+	r, err := blobs.Get(ctx, "backups/a.tar.gz")
+	if err != nil {
+		t.Fatalf("failed to get blob; %s", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "first" {
+		t.Errorf("expected blob 'first', got '%s'", data)
+	}
+
+	// ==================== Test case 2: Put replaces an existing blob ====================
+	if err := blobs.Put(ctx, "backups/a.tar.gz", strings.NewReader("second")); err != nil {
+		t.Fatalf("failed to put blob; %s", err)
+	}
+	r, err = blobs.Get(ctx, "backups/a.tar.gz")
+	if err != nil {
+		t.Fatalf("failed to get blob; %s", err)
+	}
+	data, _ = io.ReadAll(r)
+	r.Close()
+	if string(data) != "second" {
+		t.Errorf("expected blob 'second', got '%s'", data)
+	}
+
+	// ==================== Test case 3: List blobs by prefix ====================
+	for _, name := range []string{"backups/c.tar.gz", "backups/b.tar.gz", "other.txt"} {
+		if err := blobs.Put(ctx, name, strings.NewReader(name)); err != nil {
+			t.Fatalf("failed to put blob; %s", err)
+		}
+	}
+	names, err := blobs.List(ctx, "backups/")
+	if err != nil {
+		t.Fatalf("failed to list blobs; %s", err)
+	}
+	expected := []string{"backups/a.tar.gz", "backups/b.tar.gz", "backups/c.tar.gz"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("expected blobs %v, got %v", expected, names)
+	}
+
+	// ==================== Test case 4: Missing blob ====================
+	_, err = blobs.Get(ctx, "backups/missing.tar.gz")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found getting missing blob, got %v", err)
+	}
+
+	// ==================== Test case 5: Names can't escape the directory ====================
+	for _, name := range []string{"", "../escape", "/etc/passwd", "backups/../../escape"} {
+		if err := blobs.Put(ctx, name, strings.NewReader("")); err == nil {
+			t.Errorf("expected error putting blob '%s'", name)
+		}
+		if _, err := blobs.Get(ctx, name); err == nil {
+			t.Errorf("expected error getting blob '%s'", name)
+		}
+	}
+}
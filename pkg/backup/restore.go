@@ -0,0 +1,196 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/georgemblack/web-api/pkg/render"
+	"github.com/georgemblack/web-api/pkg/repo"
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// RestoreReport describes the changes made by a restore, or that would be made by a dry run.
+type RestoreReport struct {
+	Name   string  `json:"name"`
+	DryRun bool    `json:"dryRun"`
+	Posts  Changes `json:"posts"`
+	Likes  Changes `json:"likes"`
+	// Sanitized maps the IDs of posts to the markup stripped from their content when they were rendered.
+	// Posts with nothing stripped are left out.
+	Sanitized map[string]render.Report `json:"sanitized"`
+}
+
+// Changes lists the IDs of records created or updated by a restore, and of those skipped due to conflicts.
+type Changes struct {
+	Created   []string   `json:"created"`
+	Updated   []string   `json:"updated"`
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+// Conflict describes why a record couldn't be restored.
+type Conflict struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// Restore restores the named archive, or the most recent archive in the service's namespace if the name is empty.
+// Returns ErrNotFound if there is no such archive in the namespace, and ErrInvalidArchive if it can't be read.
+func (s *Service) Restore(ctx context.Context, name string, dryRun bool) (RestoreReport, error) {
+	archive, name, err := s.Archive(ctx, name)
+	if err != nil {
+		return RestoreReport{}, err
+	}
+
+	report, err := RestoreArchive(s.store, s.renderer, archive, dryRun)
+	report.Name = name
+	return report, err
+}
+
+// Archive reads the named archive, or the most recent archive in the service's namespace if the name is empty,
+// and returns it along with its name. Returns errors as Restore does.
+func (s *Service) Archive(ctx context.Context, name string) (Archive, string, error) {
+	// Environments may share a bucket, so archives in other namespaces are never restored
+	if name != "" && (!strings.HasPrefix(name, s.prefix) || path.Clean(name) != name) {
+		return Archive{}, "", fmt.Errorf("archive '%s' is not in namespace '%s'; %w", name, s.prefix, ErrNotFound)
+	}
+	if name == "" {
+		names, err := s.blobs.List(ctx, s.prefix)
+		if err != nil {
+			return Archive{}, "", types.WrapErr(err, "failed to list archives")
+		}
+		if len(names) == 0 {
+			return Archive{}, "", fmt.Errorf("no archives to restore; %w", ErrNotFound)
+		}
+		name = names[len(names)-1]
+	}
+
+	r, err := s.blobs.Get(ctx, name)
+	if err != nil {
+		return Archive{}, "", err
+	}
+	defer r.Close()
+	archive, err := ReadArchive(r)
+	if err != nil {
+		return Archive{}, "", err
+	}
+	return archive, name, nil
+}
+
+// RestoreArchive upserts every post and like in an archive, keeping their original IDs. Posts whose slug is
+// used by a different post, either in the store or earlier in the archive, are reported as conflicts and skipped,
+// as are invalid likes. The HTML of posts is rendered again from their content rather than trusted from the archive.
+// In a dry run, the changes are reported without writing anything.
+func RestoreArchive(store repo.Store, renderer *render.Renderer, archive Archive, dryRun bool) (RestoreReport, error) {
+	report := RestoreReport{DryRun: dryRun, Posts: newChanges(), Likes: newChanges(), Sanitized: make(map[string]render.Report)}
+	if err := restorePosts(store, renderer, archive.Posts, dryRun, &report); err != nil {
+		return report, err
+	}
+	if err := restoreLikes(store, archive.Likes, dryRun, &report.Likes); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func restorePosts(store repo.Store, renderer *render.Renderer, posts []types.Post, dryRun bool, report *RestoreReport) error {
+	changes := &report.Posts
+	current, err := store.GetPosts(repo.PostFilters{})
+	if err != nil {
+		return types.WrapErr(err, "failed to get posts")
+	}
+	exists := make(map[string]bool, len(current))
+	for _, post := range posts {
+		exists[post.ID] = false
+	}
+	// Posts being restored may change their slug, so only the slugs of other posts are taken
+	slugs := make(map[string]string, len(current))
+	for _, post := range current {
+		if _, restoring := exists[post.ID]; restoring {
+			exists[post.ID] = true
+			continue
+		}
+		slugs[post.Slug] = post.ID
+	}
+
+	for _, post := range posts {
+		if post.ID == "" {
+			changes.Conflicts = append(changes.Conflicts, Conflict{Reason: fmt.Sprintf("post '%s' has no id", post.Title)})
+			continue
+		}
+		if owner, ok := slugs[post.Slug]; ok && owner != post.ID {
+			changes.Conflicts = append(changes.Conflicts, Conflict{ID: post.ID, Reason: fmt.Sprintf("slug '%s' is used by post '%s'", post.Slug, owner)})
+			continue
+		}
+		if post.Slug != "" {
+			slugs[post.Slug] = post.ID
+		}
+
+		rendered, removed, err := renderer.Post(post)
+		if err != nil {
+			return types.WrapErr(err, fmt.Sprintf("failed to render post '%s'", post.ID))
+		}
+		post = rendered
+		if len(removed) > 0 {
+			report.Sanitized[post.ID] = removed
+		}
+
+		if !dryRun {
+			err := store.PutPost(post)
+			if errors.Is(err, repo.ErrConflict) {
+				changes.Conflicts = append(changes.Conflicts, Conflict{ID: post.ID, Reason: err.Error()})
+				continue
+			}
+			if err != nil {
+				return types.WrapErr(err, fmt.Sprintf("failed to restore post '%s'", post.ID))
+			}
+		}
+		if exists[post.ID] {
+			changes.Updated = append(changes.Updated, post.ID)
+		} else {
+			changes.Created = append(changes.Created, post.ID)
+		}
+		exists[post.ID] = true
+	}
+	return nil
+}
+
+func restoreLikes(store repo.Store, likes []types.Like, dryRun bool, changes *Changes) error {
+	current, err := store.GetLikes()
+	if err != nil {
+		return types.WrapErr(err, "failed to get likes")
+	}
+	exists := make(map[string]bool, len(current))
+	for _, like := range current {
+		exists[like.ID] = true
+	}
+
+	for _, like := range likes {
+		if like.ID == "" {
+			changes.Conflicts = append(changes.Conflicts, Conflict{Reason: fmt.Sprintf("like '%s' has no id", like.Title)})
+			continue
+		}
+		if err := like.Validate(); err != nil {
+			changes.Conflicts = append(changes.Conflicts, Conflict{ID: like.ID, Reason: err.Error()})
+			continue
+		}
+		if !dryRun {
+			if err := store.PutLike(like); err != nil {
+				return types.WrapErr(err, fmt.Sprintf("failed to restore like '%s'", like.ID))
+			}
+		}
+		if exists[like.ID] {
+			changes.Updated = append(changes.Updated, like.ID)
+		} else {
+			changes.Created = append(changes.Created, like.ID)
+		}
+		exists[like.ID] = true
+	}
+	return nil
+}
+
+// newChanges returns empty changes, which encode as empty lists rather than null.
+func newChanges() Changes {
+	return Changes{Created: []string{}, Updated: []string{}, Conflicts: []Conflict{}}
+}
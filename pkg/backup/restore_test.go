@@ -0,0 +1,190 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/render"
+	"github.com/georgemblack/web-api/pkg/repo"
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	service, blobs, store := newTestService(t)
+	result, err := service.Backup(ctx)
+	if err != nil {
+		t.Fatalf("failed to back up; %s", err)
+	}
+	posts, _ := store.GetPosts(repo.PostFilters{})
+	likes, _ := store.GetLikes()
+
+	// ==================== Test case 1: Restoring to an empty store creates everything ====================
+	empty := repo.NewMemoryService()
+	restore := NewService(empty, blobs, render.NewRenderer(conf.Config{}), "test")
+	report, err := restore.Restore(ctx, "", true)
+	if err != nil {
+		t.Fatalf("failed to restore; %s", err)
+	}
+	if report.Name != result.Name || !report.DryRun {
+		t.Errorf("expected dry run of %s, got %+v", result.Name, report)
+	}
+	if len(report.Posts.Created) != 2 || len(report.Likes.Created) != 2 {
+		t.Errorf("expected 2 posts and 2 likes created, got %+v", report)
+	}
+
+	// Dry runs don't write anything
+	if actual, _ := empty.GetPosts(repo.PostFilters{}); len(actual) != 0 {
+		t.Errorf("expected no posts after dry run, got %d", len(actual))
+	}
+
+	report, err = restore.Restore(ctx, result.Name, false)
+	if err != nil {
+		t.Fatalf("failed to restore; %s", err)
+	}
+	if len(report.Posts.Created) != 2 || len(report.Likes.Created) != 2 {
+		t.Errorf("expected 2 posts and 2 likes created, got %+v", report)
+	}
+	for _, post := range posts {
+		actual, err := empty.GetPost(post.ID)
+		if err != nil {
+			t.Errorf("expected post %s to be restored with its id, got %v", post.ID, err)
+		}
+		if actual.Title != post.Title || actual.Slug != post.Slug || actual.Draft != post.Draft {
+			t.Errorf("expected post %+v, got %+v", post, actual)
+		}
+	}
+	for _, like := range likes {
+		if _, err := empty.GetLike(like.ID); err != nil {
+			t.Errorf("expected like %s to be restored with its id, got %v", like.ID, err)
+		}
+	}
+
+	// ==================== Test case 2: Restoring again updates everything ====================
+	report, err = restore.Restore(ctx, result.Name, false)
+	if err != nil {
+		t.Fatalf("failed to restore; %s", err)
+	}
+	if len(report.Posts.Created) != 0 || len(report.Posts.Updated) != 2 || len(report.Likes.Updated) != 2 {
+		t.Errorf("expected 2 posts and 2 likes updated, got %+v", report)
+	}
+
+	// ==================== Test case 3: Posts whose slug is taken are conflicts ====================
+	conflicting := repo.NewMemoryService()
+	otherID, err := conflicting.AddPost(types.Post{Title: "other", Slug: posts[0].Slug})
+	if err != nil {
+		t.Fatalf("failed to add post; %s", err)
+	}
+	for _, dryRun := range []bool{true, false} {
+		report, err = NewService(conflicting, blobs, render.NewRenderer(conf.Config{}), "test").Restore(ctx, result.Name, dryRun)
+		if err != nil {
+			t.Fatalf("failed to restore; %s", err)
+		}
+		if len(report.Posts.Conflicts) != 1 || report.Posts.Conflicts[0].ID != posts[0].ID {
+			t.Errorf("expected conflict for post %s, got %+v", posts[0].ID, report.Posts.Conflicts)
+		}
+		if !slices.Equal(report.Posts.Created, []string{posts[1].ID}) {
+			t.Errorf("expected post %s created, got %v", posts[1].ID, report.Posts.Created)
+		}
+	}
+	if actual, _ := conflicting.GetPost(otherID); actual.Title != "other" {
+		t.Errorf("expected conflicting post to be unchanged, got %+v", actual)
+	}
+	if _, err := conflicting.GetPost(posts[0].ID); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected conflicting post not to be restored, got %v", err)
+	}
+
+	// ==================== Test case 4: Missing archives ====================
+	if _, err := restore.Restore(ctx, Prefix+"test/missing.tar.gz", true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found restoring missing archive, got %v", err)
+	}
+	emptyBlobs, _ := NewLocalStore(t.TempDir())
+	if _, err := NewService(empty, emptyBlobs, render.NewRenderer(conf.Config{}), "test").Restore(ctx, "", true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found restoring without archives, got %v", err)
+	}
+
+	// ==================== Test case 5: Archives in other namespaces are never restored by default ====================
+	if _, err := NewService(empty, blobs, render.NewRenderer(conf.Config{}), "other").Restore(ctx, "", true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found restoring without archives in namespace, got %v", err)
+	}
+
+	// ==================== Test case 6: Archives in other namespaces can't be restored by name ====================
+	other := NewService(empty, blobs, render.NewRenderer(conf.Config{}), "other")
+	escaped := Prefix + "other/../test/" + strings.TrimPrefix(result.Name, Prefix+"test/")
+	for _, name := range []string{result.Name, escaped} {
+		if _, err := other.Restore(ctx, name, true); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected not found restoring %s from another namespace, got %v", name, err)
+		}
+	}
+}
+
+func TestRestoreArchive(t *testing.T) {
+	store := repo.NewMemoryService()
+	renderer := render.NewRenderer(conf.Config{})
+
+	// Records without IDs, posts sharing a slug within the archive, and invalid likes are conflicts
+	archive := Archive{
+		Posts: []types.Post{
+			{ID: "a", Title: "first", Slug: "same"},
+			{ID: "b", Title: "second", Slug: "same"},
+			{Title: "no id", Slug: "no-id"},
+		},
+		Likes: []types.Like{
+			{ID: "c", Title: "like", URL: "https://example.com"},
+			{Title: "no id", URL: "https://example.com"},
+			{ID: "d", Title: "invalid", URL: "javascript:alert(1)"},
+		},
+	}
+	report, err := RestoreArchive(store, renderer, archive, false)
+	if err != nil {
+		t.Fatalf("failed to restore; %s", err)
+	}
+	if !slices.Equal(report.Posts.Created, []string{"a"}) || len(report.Posts.Conflicts) != 2 {
+		t.Errorf("expected post 'a' created and 2 conflicts, got %+v", report.Posts)
+	}
+	if !slices.Equal(report.Likes.Created, []string{"c"}) || len(report.Likes.Conflicts) != 2 {
+		t.Errorf("expected like 'c' created and 2 conflicts, got %+v", report.Likes)
+	}
+	if _, err := store.GetLike("d"); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected invalid like not to be restored, got %v", err)
+	}
+
+	// Restored posts may change their own slug
+	renamed := Archive{Posts: []types.Post{{ID: "a", Title: "first", Slug: "other"}}}
+	if _, err := store.AddPost(types.Post{Title: "unrelated", Slug: "unrelated"}); err != nil {
+		t.Fatalf("failed to add post; %s", err)
+	}
+	report, err = RestoreArchive(store, renderer, renamed, false)
+	if err != nil {
+		t.Fatalf("failed to restore; %s", err)
+	}
+	if !slices.Equal(report.Posts.Updated, []string{"a"}) || len(report.Posts.Conflicts) != 0 {
+		t.Errorf("expected post 'a' updated, got %+v", report.Posts)
+	}
+	if len(report.Sanitized) != 0 {
+		t.Errorf("expected nothing sanitized, got %+v", report.Sanitized)
+	}
+
+	// HTML is rendered from the content, and sanitized, rather than restored as-is
+	unsafe := Archive{Posts: []types.Post{
+		{ID: "e", Title: "unsafe", Slug: "unsafe", Content: "Hello <script>alert(1)</script>", ContentHTML: "<script>alert(2)</script>"},
+	}}
+	report, err = RestoreArchive(store, renderer, unsafe, false)
+	if err != nil {
+		t.Fatalf("failed to restore; %s", err)
+	}
+	post, err := store.GetPost("e")
+	if err != nil {
+		t.Fatalf("failed to get restored post; %s", err)
+	}
+	if strings.Contains(post.ContentHTML, "script") || strings.Contains(post.ContentHTMLPreview, "script") || !strings.Contains(post.ContentHTML, "Hello") {
+		t.Errorf("expected sanitized html rendered from content, got '%s'", post.ContentHTML)
+	}
+	if removed := report.Sanitized["e"]; len(removed) != 1 || removed[0].Element != "script" {
+		t.Errorf("expected script reported as sanitized, got %+v", report.Sanitized)
+	}
+}
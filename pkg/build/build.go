@@ -0,0 +1,200 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultDelay is how long a trigger waits after the most recent request before starting a build, unless configured otherwise.
+const DefaultDelay = 10 * time.Second
+
+// backoff configures how failed builds are retried.
+type backoff struct {
+	attempts int
+	initial  time.Duration
+	max      time.Duration
+}
+
+var defaultBackoff = backoff{
+	attempts: 4,
+	initial:  time.Second,
+	max:      30 * time.Second,
+}
+
+// Status describes the state of a trigger, and the outcome of its most recent build.
+type Status struct {
+	// Enabled is false if no build service endpoint is configured.
+	Enabled bool `json:"enabled"`
+	// Pending is true if a build has been requested, but hasn't started.
+	Pending       bool      `json:"pending"`
+	Running       bool      `json:"running"`
+	LastRequested time.Time `json:"lastRequested"`
+	LastBuild     *Build    `json:"lastBuild"`
+}
+
+// Build is the outcome of a single build, which may have taken several attempts.
+type Build struct {
+	Started   time.Time `json:"started"`
+	Finished  time.Time `json:"finished"`
+	Attempts  int       `json:"attempts"`
+	Succeeded bool      `json:"succeeded"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Trigger starts builds of the static site by POSTing to the build service.
+// Requests are debounced, so a burst of changes results in a single build once they stop.
+type Trigger struct {
+	endpoint string
+	delay    time.Duration
+	backoff  backoff
+	client   *http.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// building is held for the duration of a build, so builds never overlap
+	building sync.Mutex
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	timer *time.Timer
+	// requests counts requests, so a run can tell whether a newer request is still waiting
+	requests int
+	closed   bool
+	status   Status
+}
+
+// NewTrigger creates a trigger for the build service at the given endpoint, which waits for the delay
+// after the most recent request before starting a build. If the endpoint is empty, requests are ignored.
+func NewTrigger(endpoint string, delay time.Duration) *Trigger {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Trigger{
+		endpoint: endpoint,
+		delay:    delay,
+		backoff:  defaultBackoff,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		ctx:      ctx,
+		cancel:   cancel,
+		status:   Status{Enabled: endpoint != ""},
+	}
+}
+
+// Request schedules a build, postponing any build that is still waiting for the delay to pass.
+func (t *Trigger) Request() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.status.Enabled || t.closed {
+		return
+	}
+	t.status.Pending = true
+	t.status.LastRequested = time.Now()
+	if t.timer != nil && t.timer.Stop() {
+		t.wg.Done()
+	}
+	t.requests++
+	request := t.requests
+	t.wg.Add(1)
+	t.timer = time.AfterFunc(t.delay, func() { t.run(request) })
+}
+
+// Status returns the current state of the trigger.
+func (t *Trigger) Status() Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := t.status
+	if status.LastBuild != nil {
+		build := *status.LastBuild
+		status.LastBuild = &build
+	}
+	return status
+}
+
+// Close cancels any pending build, and stops retrying a running one, then waits for it to finish.
+func (t *Trigger) Close() {
+	t.mu.Lock()
+	t.closed = true
+	if t.timer != nil && t.timer.Stop() {
+		t.status.Pending = false
+		t.wg.Done()
+	}
+	t.mu.Unlock()
+
+	t.cancel()
+	t.wg.Wait()
+}
+
+// run performs a build for a request, retrying failed attempts. The build stays pending if a newer
+// request is waiting for its own run, such as when this run waited for an earlier build to finish.
+func (t *Trigger) run(request int) {
+	defer t.wg.Done()
+	t.building.Lock()
+	defer t.building.Unlock()
+
+	t.mu.Lock()
+	if request == t.requests {
+		t.status.Pending = false
+	}
+	t.status.Running = true
+	t.mu.Unlock()
+
+	build := Build{Started: time.Now()}
+	err := t.post(&build)
+	build.Finished = time.Now()
+	build.Succeeded = err == nil
+	if err != nil {
+		build.Error = err.Error()
+		slog.Error(fmt.Sprintf("failed to trigger build after %d attempts; %s", build.Attempts, err))
+	} else {
+		slog.Info(fmt.Sprintf("triggered build after %d attempts", build.Attempts))
+	}
+
+	t.mu.Lock()
+	t.status.Running = false
+	t.status.LastBuild = &build
+	t.mu.Unlock()
+}
+
+// post calls the build service until it succeeds, returns an error that is not retryable, or runs out of attempts.
+// The delay between attempts doubles each time, up to the maximum.
+func (t *Trigger) post(build *Build) error {
+	delay := t.backoff.initial
+	for {
+		build.Attempts++
+		retry, err := t.postOnce()
+		if err == nil || !retry || build.Attempts >= t.backoff.attempts {
+			return err
+		}
+
+		slog.Warn(fmt.Sprintf("failed to trigger build, retrying in %s; %s", delay, err))
+		select {
+		case <-t.ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, t.backoff.max)
+	}
+}
+
+// postOnce makes a single request to the build service, and reports whether a failure is worth retrying.
+func (t *Trigger) postOnce() (bool, error) {
+	req, err := http.NewRequestWithContext(t.ctx, http.MethodPost, t.endpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create build request; %w", err)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return t.ctx.Err() == nil, fmt.Errorf("failed to call build service; %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("build service responded with status %d", resp.StatusCode)
+}
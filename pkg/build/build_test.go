@@ -0,0 +1,151 @@
+package build
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestTrigger creates a trigger for a stand-in build service, which responds with each of the
+// status codes in turn, then with 200. Returns the trigger and a count of requests received.
+func newTestTrigger(t *testing.T, codes ...int) (*Trigger, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST to build service, got %s", r.Method)
+		}
+		call := int(calls.Add(1))
+		if call <= len(codes) {
+			w.WriteHeader(codes[call-1])
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	trigger := NewTrigger(server.URL, 20*time.Millisecond)
+	trigger.backoff = backoff{attempts: 3, initial: time.Millisecond, max: time.Millisecond}
+	t.Cleanup(trigger.Close)
+	return trigger, &calls
+}
+
+// waitForBuild waits until the trigger has finished a build, and returns its status.
+func waitForBuild(t *testing.T, trigger *Trigger) Status {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		status := trigger.Status()
+		if status.LastBuild != nil && !status.Pending && !status.Running {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for build")
+	return Status{}
+}
+
+func TestTrigger(t *testing.T) {
+	// ==================== Test case 1: Bursts of requests are debounced into one build ====================
+	trigger, calls := newTestTrigger(t)
+	for i := 0; i < 5; i++ {
+		trigger.Request()
+	}
+	if status := trigger.Status(); !status.Enabled || !status.Pending || status.LastRequested.IsZero() {
+		t.Errorf("expected enabled trigger with pending build, got %+v", status)
+	}
+	status := waitForBuild(t, trigger)
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 call to build service, got %d", calls.Load())
+	}
+	if !status.LastBuild.Succeeded || status.LastBuild.Attempts != 1 || status.LastBuild.Error != "" {
+		t.Errorf("expected successful build in 1 attempt, got %+v", status.LastBuild)
+	}
+
+	// ==================== Test case 2: Requests after a build start another ====================
+	trigger.Request()
+	time.Sleep(100 * time.Millisecond)
+	waitForBuild(t, trigger)
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 calls to build service, got %d", calls.Load())
+	}
+
+	// ==================== Test case 3: Server errors are retried ====================
+	trigger, calls = newTestTrigger(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	trigger.Request()
+	status = waitForBuild(t, trigger)
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 calls to build service, got %d", calls.Load())
+	}
+	if !status.LastBuild.Succeeded || status.LastBuild.Attempts != 3 {
+		t.Errorf("expected successful build in 3 attempts, got %+v", status.LastBuild)
+	}
+
+	// ==================== Test case 4: Builds fail once out of attempts ====================
+	trigger, calls = newTestTrigger(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	trigger.Request()
+	status = waitForBuild(t, trigger)
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 calls to build service, got %d", calls.Load())
+	}
+	if status.LastBuild.Succeeded || status.LastBuild.Error == "" {
+		t.Errorf("expected failed build with error, got %+v", status.LastBuild)
+	}
+
+	// ==================== Test case 5: Client errors are not retried ====================
+	trigger, calls = newTestTrigger(t, http.StatusUnauthorized)
+	trigger.Request()
+	status = waitForBuild(t, trigger)
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 call to build service, got %d", calls.Load())
+	}
+	if status.LastBuild.Succeeded || status.LastBuild.Attempts != 1 {
+		t.Errorf("expected failed build in 1 attempt, got %+v", status.LastBuild)
+	}
+
+	// ==================== Test case 6: Closing cancels pending builds ====================
+	trigger, calls = newTestTrigger(t)
+	trigger.Request()
+	trigger.Close()
+	trigger.Request()
+	time.Sleep(100 * time.Millisecond)
+	if calls.Load() != 0 {
+		t.Errorf("expected no calls to build service, got %d", calls.Load())
+	}
+	if status := trigger.Status(); status.Pending || status.LastBuild != nil {
+		t.Errorf("expected no pending or finished builds, got %+v", status)
+	}
+
+	// ==================== Test case 7: Builds stay pending while a newer request waits ====================
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+	defer server.Close()
+	trigger = NewTrigger(server.URL, 10*time.Millisecond)
+	trigger.Request()
+	<-started
+
+	// The second run fires while the first build is running, so waits for it
+	trigger.Request()
+	time.Sleep(50 * time.Millisecond)
+
+	// The third request is still waiting when the second run starts
+	trigger.delay = time.Hour
+	trigger.Request()
+	release <- struct{}{}
+	<-started
+	if status := trigger.Status(); !status.Pending || !status.Running {
+		t.Errorf("expected running build with another pending, got %+v", status)
+	}
+	close(release)
+	trigger.Close()
+
+	// ==================== Test case 8: Triggers without an endpoint are disabled ====================
+	trigger = NewTrigger("", 0)
+	trigger.Request()
+	if status := trigger.Status(); status.Enabled || status.Pending {
+		t.Errorf("expected disabled trigger with nothing pending, got %+v", status)
+	}
+	trigger.Close()
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// OpenAPI gets the OpenAPI document describing the API.
+func (c *Client) OpenAPI(ctx context.Context) ([]byte, error) {
+	var spec []byte
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/openapi.json", public: true}, &spec)
+	return spec, err
+}
+
+// HighlightCSS gets the stylesheet for a code highlighting theme, or the default theme if it's empty.
+func (c *Client) HighlightCSS(ctx context.Context, theme string) ([]byte, error) {
+	query := url.Values{}
+	if theme != "" {
+		query.Set("theme", theme)
+	}
+	var css []byte
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/styles/highlight.css", query: query, public: true}, &css)
+	return css, err
+}
+
+// GetBuilds gets the status of static site builds.
+func (c *Client) GetBuilds(ctx context.Context) (BuildStatus, error) {
+	var resp BuildStatus
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/builds"}, &resp)
+	return resp, err
+}
+
+// Backup backs up every post and like.
+func (c *Client) Backup(ctx context.Context) (BackupResult, error) {
+	var resp BackupResult
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/admin/backups"}, &resp)
+	return resp, err
+}
+
+// Restore restores a backup archive by name, or the most recent archive if the name is empty.
+// A dry run reports the changes without writing anything.
+func (c *Client) Restore(ctx context.Context, name string, dryRun bool) (RestoreReport, error) {
+	query := url.Values{}
+	if dryRun {
+		query.Set("dryRun", strconv.FormatBool(dryRun))
+	}
+	var resp RestoreReport
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/admin/restore", query: query, body: types.RestoreRequest{Name: name}}, &resp)
+	return resp, err
+}
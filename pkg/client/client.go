@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tokenLifetime is how long tokens issued by the API are valid, assumed if a token doesn't say when it expires.
+const tokenLifetime = 2 * time.Hour
+
+// refreshBefore is how long before a token expires that it's replaced, so requests never race its expiry.
+const refreshBefore = 5 * time.Minute
+
+// Client calls the API, logging in with a username and password as needed.
+// Tokens are cached and shared by every request, and a Client is safe for concurrent use.
+type Client struct {
+	baseURL  string
+	username string
+	password string
+	http     *http.Client
+
+	// mu is held while logging in, so concurrent requests share a single login
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// New creates a client of the API at the base URL. If httpClient is nil, http.DefaultClient is used.
+func New(baseURL string, username string, password string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		username: username,
+		password: password,
+		http:     httpClient,
+	}
+}
+
+// Token returns a token for the API, logging in if there's no cached token or it's about to expire.
+func (c *Client) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Until(c.expires) > refreshBefore {
+		return c.token, nil
+	}
+
+	var resp types.AuthResponse
+	_, err := c.send(ctx, request{method: http.MethodPost, path: "/auth", basicAuth: true}, nil, &resp)
+	if err != nil {
+		return "", types.WrapErr(err, "failed to log in")
+	}
+	c.token = resp.Token
+	c.expires = expiry(resp.Token, time.Now())
+	return c.token, nil
+}
+
+// invalidate discards a cached token, unless it's already been replaced.
+func (c *Client) invalidate(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+// expiry reads when a token expires. Tokens are signed by the API, so their claims are read without verifying them.
+func expiry(token string, issued time.Time) time.Time {
+	var claims jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil || claims.ExpiresAt == nil {
+		return issued.Add(tokenLifetime)
+	}
+	return claims.ExpiresAt.Time
+}
+
+// request describes a call to the API.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	// ifMatch makes a write conditional on the entity tag of the resource
+	ifMatch string
+	// public requests are sent without a token
+	public bool
+	// basicAuth requests are sent with the username and password, to log in
+	basicAuth bool
+	token     string
+}
+
+// do sends a request with a token, and decodes the JSON response into out, unless out is nil.
+// If the token is rejected, such as when the API's signing secret has changed, it logs in again and retries once.
+func (c *Client) do(ctx context.Context, req request, out any) (http.Header, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		body, err = json.Marshal(req.body)
+		if err != nil {
+			return nil, types.WrapErr(err, "failed to encode request")
+		}
+	}
+	if req.public {
+		return c.send(ctx, req, body, out)
+	}
+
+	for attempt := 1; ; attempt++ {
+		token, err := c.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.token = token
+		header, err := c.send(ctx, req, body, out)
+		if attempt == 1 && isUnauthorized(err) {
+			c.invalidate(token)
+			continue
+		}
+		return header, err
+	}
+}
+
+// send sends a single request, returning the response headers. Error responses are returned as an *Error.
+func (c *Client) send(ctx context.Context, req request, body []byte, out any) (http.Header, error) {
+	u := c.baseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+	r, err := http.NewRequestWithContext(ctx, req.method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, types.WrapErr(err, "failed to create request")
+	}
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if req.ifMatch != "" {
+		r.Header.Set("If-Match", req.ifMatch)
+	}
+	switch {
+	case req.basicAuth:
+		r.SetBasicAuth(c.username, c.password)
+	case req.token != "":
+		r.Header.Set("Authorization", "Bearer "+req.token)
+	}
+
+	resp, err := c.http.Do(r)
+	if err != nil {
+		return nil, types.WrapErr(err, "failed to send request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return resp.Header, newError(resp)
+	}
+	switch out := out.(type) {
+	case nil:
+		_, err = io.Copy(io.Discard, resp.Body)
+	case *[]byte:
+		*out, err = io.ReadAll(resp.Body)
+	default:
+		err = json.NewDecoder(resp.Body).Decode(out)
+	}
+	if err != nil {
+		return resp.Header, types.WrapErr(err, "failed to read response")
+	}
+	return resp.Header, nil
+}
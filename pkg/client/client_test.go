@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newTestServer stands in for the API, issuing tokens valid for the lifetime and accepting only the latest token.
+func newTestServer(t *testing.T, lifetime time.Duration) (*httptest.Server, *atomic.Int32) {
+	var logins atomic.Int32
+	var current atomic.Value
+	current.Store("")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"timestamp":"2024-01-01T00:00:00Z","message":"Unauthorized","requestId":"abc"}`))
+			return
+		}
+		n := logins.Add(1)
+		claims := jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(lifetime)), ID: string(rune('0' + n))}
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		current.Store(token)
+		w.Write([]byte(`{"token":"` + token + `"}`))
+	})
+	mux.HandleFunc("/likes/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"timestamp":"2024-01-01T00:00:00Z","message":"Not found","requestId":"def"}`))
+	})
+	mux.HandleFunc("/likes/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+current.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"like":{"id":"1","title":"Like","url":"https://example.com"}}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &logins
+}
+
+func TestToken(t *testing.T) {
+	ctx := context.Background()
+
+	// ==================== Test case 1: Tokens are cached ====================
+	server, logins := newTestServer(t, tokenLifetime)
+	client := New(server.URL, "user", "pass", nil)
+	for i := 0; i < 3; i++ {
+		like, err := client.GetLike(ctx, "1")
+		if err != nil {
+			t.Fatalf("failed to get like; %s", err)
+		}
+		if like.ID != "1" || like.Title != "Like" {
+			t.Errorf("expected like 1, got %+v", like)
+		}
+	}
+	if logins.Load() != 1 {
+		t.Errorf("expected 1 login, got %d", logins.Load())
+	}
+
+	// ==================== Test case 2: Tokens about to expire are refreshed ====================
+	server, logins = newTestServer(t, refreshBefore-time.Minute)
+	client = New(server.URL, "user", "pass", nil)
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetLike(ctx, "1"); err != nil {
+			t.Fatalf("failed to get like; %s", err)
+		}
+	}
+	if logins.Load() != 3 {
+		t.Errorf("expected 3 logins, got %d", logins.Load())
+	}
+
+	// ==================== Test case 3: Rejected tokens are replaced ====================
+	server, logins = newTestServer(t, tokenLifetime)
+	client = New(server.URL, "user", "pass", nil)
+	client.token = "revoked"
+	client.expires = time.Now().Add(time.Hour)
+	if _, err := client.GetLike(ctx, "1"); err != nil {
+		t.Fatalf("failed to get like; %s", err)
+	}
+	if logins.Load() != 1 {
+		t.Errorf("expected 1 login, got %d", logins.Load())
+	}
+
+	// ==================== Test case 4: Invalid credentials ====================
+	client = New(server.URL, "user", "wrong", nil)
+	_, err := client.GetLike(ctx, "1")
+	var apiErr *Error
+	if !errors.Is(err, ErrUnauthorized) || !errors.As(err, &apiErr) || apiErr.RequestID != "abc" {
+		t.Errorf("expected unauthorized error with request id, got %v", err)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	issued := time.Now()
+	expires := issued.Add(time.Hour).Truncate(time.Second)
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(expires)}).SignedString([]byte("secret"))
+	if actual := expiry(token, issued); !actual.Equal(expires) {
+		t.Errorf("expected expiry %s, got %s", expires, actual)
+	}
+	if actual := expiry("bogus", issued); !actual.Equal(issued.Add(tokenLifetime)) {
+		t.Errorf("expected expiry after token lifetime, got %s", actual)
+	}
+}
+
+func TestError(t *testing.T) {
+	ctx := context.Background()
+	server, _ := newTestServer(t, tokenLifetime)
+	client := New(server.URL, "user", "pass", nil)
+
+	// ==================== Test case 1: Error responses are decoded ====================
+	_, err := client.GetLike(ctx, "missing")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	if !errors.Is(err, ErrNotFound) || apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "Not found" || apiErr.RequestID != "def" {
+		t.Errorf("expected not found error, got %+v", apiErr)
+	}
+
+	// ==================== Test case 2: Errors without a body are described by their status ====================
+	_, err = client.GetPosts(ctx, PostFilters{}, Page{})
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrNotFound) || apiErr.Message != "Not Found" {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
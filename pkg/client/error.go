@@ -0,0 +1,68 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// Errors matching the status of error responses, for use with errors.Is.
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrServer             = errors.New("server error")
+)
+
+// Error is an error response from the API.
+type Error struct {
+	StatusCode int
+	types.ErrorResponse
+}
+
+// newError reads an error response. Responses without an error body, such as those for unknown routes,
+// are described by their status.
+func newError(resp *http.Response) *Error {
+	e := &Error{StatusCode: resp.StatusCode}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || json.Unmarshal(body, &e.ErrorResponse) != nil || e.Message == "" {
+		e.ErrorResponse = types.ErrorResponse{Message: http.StatusText(resp.StatusCode)}
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.RequestID == "" {
+		return fmt.Sprintf("%d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%d %s (request %s)", e.StatusCode, e.Message, e.RequestID)
+}
+
+// Unwrap returns the error matching the response's status, if any.
+func (e *Error) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusConflict:
+		return ErrConflict
+	case e.StatusCode == http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	case e.StatusCode >= 500:
+		return ErrServer
+	}
+	return nil
+}
+
+func isUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
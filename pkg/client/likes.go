@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// LikesPage is a page of likes. NextCursor is empty on the last page.
+type LikesPage struct {
+	Likes      []types.Like `json:"likes"`
+	NextCursor string       `json:"nextCursor"`
+}
+
+// GetLikes gets a page of likes, most recent first.
+func (c *Client) GetLikes(ctx context.Context, page Page) (LikesPage, error) {
+	query := url.Values{}
+	page.set(query)
+	var resp LikesPage
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/likes", query: query}, &resp)
+	return resp, err
+}
+
+// GetLike gets a like by its ID.
+func (c *Client) GetLike(ctx context.Context, id string) (types.Like, error) {
+	var resp struct {
+		Like types.Like `json:"like"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/likes/" + url.PathEscape(id)}, &resp)
+	return resp.Like, err
+}
+
+// AddLike creates a like, returning its ID. Likes without a timestamp are timestamped by the API.
+func (c *Client) AddLike(ctx context.Context, like types.Like) (string, error) {
+	var resp struct {
+		ID string `json:"id"`
+	}
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/likes", body: like}, &resp)
+	return resp.ID, err
+}
+
+// DeleteLike deletes a like by its ID.
+func (c *Client) DeleteLike(ctx context.Context, id string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/likes/" + url.PathEscape(id)}, nil)
+	return err
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// PostFilters restricts the posts returned. Nil filters match every post.
+type PostFilters struct {
+	// Listed matches posts marked as listed, or not
+	Listed *bool
+	// Published matches posts that aren't drafts and whose publish date has passed, or not
+	Published *bool
+}
+
+// Page selects a page of results. A zero limit requests the API's default page size.
+type Page struct {
+	Limit  int
+	Cursor string
+}
+
+// PostsPage is a page of posts. NextCursor is empty on the last page.
+type PostsPage struct {
+	Posts      []types.Post `json:"posts"`
+	NextCursor string       `json:"nextCursor"`
+}
+
+// PostResult is a post, along with the table of contents of its content.
+type PostResult struct {
+	Post types.Post `json:"post"`
+	TOC  []Heading  `json:"toc"`
+	// ETag makes a later write conditional on the post being unchanged, when passed as ifMatch.
+	ETag string `json:"-"`
+}
+
+// WriteResult describes a post after a write.
+type WriteResult struct {
+	ID string `json:"id"`
+	// Post is the post as written, returned when patching a post or restoring a revision.
+	Post types.Post `json:"post"`
+	// Sanitized lists the markup stripped from the post's rendered content.
+	Sanitized Report `json:"sanitized"`
+	// ETag is the post's entity tag after the write. It's empty for new posts.
+	ETag string `json:"-"`
+}
+
+// RevisionsPage is a page of the revisions of a post. NextCursor is empty on the last page.
+type RevisionsPage struct {
+	Revisions  []types.RevisionSummary `json:"revisions"`
+	NextCursor string                  `json:"nextCursor"`
+}
+
+// RevisionResult is a revision of a post, along with a unified diff from its content to the current content.
+type RevisionResult struct {
+	Revision types.Revision `json:"revision"`
+	Diff     string         `json:"diff"`
+}
+
+// GetPosts gets a page of posts matching the filters, most recently published first.
+func (c *Client) GetPosts(ctx context.Context, filters PostFilters, page Page) (PostsPage, error) {
+	query := filters.query()
+	page.set(query)
+	var resp PostsPage
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/posts", query: query}, &resp)
+	return resp, err
+}
+
+// GetPost gets a post by its ID.
+func (c *Client) GetPost(ctx context.Context, id string) (PostResult, error) {
+	return c.getPost(ctx, request{method: http.MethodGet, path: "/posts/" + url.PathEscape(id)})
+}
+
+// GetPostBySlug gets the post with a slug, if it matches the filters.
+func (c *Client) GetPostBySlug(ctx context.Context, slug string, filters PostFilters) (PostResult, error) {
+	return c.getPost(ctx, request{method: http.MethodGet, path: "/posts/by-slug/" + url.PathEscape(slug), query: filters.query()})
+}
+
+// getPost gets a post, along with its entity tag.
+func (c *Client) getPost(ctx context.Context, req request) (PostResult, error) {
+	var resp PostResult
+	header, err := c.do(ctx, req, &resp)
+	if err != nil {
+		return PostResult{}, err
+	}
+	resp.ETag = header.Get("ETag")
+	return resp, nil
+}
+
+// AddPost creates a post, returning its ID. The post's HTML is rendered from its content by the API.
+func (c *Client) AddPost(ctx context.Context, post types.Post) (WriteResult, error) {
+	var resp WriteResult
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/posts", body: post}, &resp)
+	return resp, err
+}
+
+// UpdatePost replaces the post with the post's ID. If ifMatch is an entity tag, the update fails with
+// ErrPreconditionFailed if the post has changed since. An empty ifMatch makes the update unconditional.
+func (c *Client) UpdatePost(ctx context.Context, post types.Post, ifMatch string) (WriteResult, error) {
+	resp := WriteResult{ID: post.ID}
+	header, err := c.do(ctx, request{method: http.MethodPut, path: "/posts/" + url.PathEscape(post.ID), body: post, ifMatch: ifMatch}, &resp)
+	if err != nil {
+		return WriteResult{}, err
+	}
+	resp.ETag = header.Get("ETag")
+	return resp, nil
+}
+
+// PatchPost applies a JSON merge patch to a post, updating only the fields in the patch, keyed by their JSON names.
+// Fields set to nil are reset to their zero value. The update is conditional as with UpdatePost.
+func (c *Client) PatchPost(ctx context.Context, id string, patch map[string]any, ifMatch string) (WriteResult, error) {
+	return c.writePost(ctx, request{method: http.MethodPatch, path: "/posts/" + url.PathEscape(id), body: patch, ifMatch: ifMatch})
+}
+
+// DeletePost deletes a post by its ID.
+func (c *Client) DeletePost(ctx context.Context, id string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/posts/" + url.PathEscape(id)}, nil)
+	return err
+}
+
+// GetRevisions gets a page of the revisions of a post, most recent first, without their content.
+func (c *Client) GetRevisions(ctx context.Context, id string, page Page) (RevisionsPage, error) {
+	query := url.Values{}
+	page.set(query)
+	var resp RevisionsPage
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/posts/" + url.PathEscape(id) + "/revisions", query: query}, &resp)
+	return resp, err
+}
+
+// GetRevision gets a revision of a post, along with a diff from its content to the current content.
+func (c *Client) GetRevision(ctx context.Context, id string, rev string) (RevisionResult, error) {
+	var resp RevisionResult
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/posts/" + url.PathEscape(id) + "/revisions/" + url.PathEscape(rev)}, &resp)
+	return resp, err
+}
+
+// RestoreRevision replaces a post with one of its revisions. The update is conditional as with UpdatePost.
+func (c *Client) RestoreRevision(ctx context.Context, id string, rev string, ifMatch string) (WriteResult, error) {
+	return c.writePost(ctx, request{method: http.MethodPost, path: "/posts/" + url.PathEscape(id) + "/revisions/" + url.PathEscape(rev) + "/restore", ifMatch: ifMatch})
+}
+
+// writePost makes a write that responds with the post as written, along with its new entity tag.
+func (c *Client) writePost(ctx context.Context, req request) (WriteResult, error) {
+	var resp WriteResult
+	header, err := c.do(ctx, req, &resp)
+	if err != nil {
+		return WriteResult{}, err
+	}
+	resp.ID = resp.Post.ID
+	resp.ETag = header.Get("ETag")
+	return resp, nil
+}
+
+// query encodes the filters as query params.
+func (f PostFilters) query() url.Values {
+	query := url.Values{}
+	if f.Listed != nil {
+		query.Set("listed", strconv.FormatBool(*f.Listed))
+	}
+	if f.Published != nil {
+		query.Set("published", strconv.FormatBool(*f.Published))
+	}
+	return query
+}
+
+// set adds the page's limit and cursor to query params.
+func (p Page) set(query url.Values) {
+	if p.Limit > 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+}
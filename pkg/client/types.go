@@ -0,0 +1,84 @@
+package client
+
+import "time"
+
+// The types below mirror responses of the API, so the client needn't depend on the server's packages.
+
+// Heading is an entry in a post's table of contents.
+type Heading struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor"`
+}
+
+// Removal describes markup stripped by the sanitizer. If Attribute is empty, the element itself was removed.
+type Removal struct {
+	Element   string `json:"element"`
+	Attribute string `json:"attribute,omitempty"`
+	Count     int    `json:"count"`
+}
+
+// Report lists the markup stripped by the sanitizer, in the order it was first found.
+type Report []Removal
+
+// BuildStatus describes whether a build of the static site is pending or running, and the outcome of the last one.
+type BuildStatus struct {
+	// Enabled is false if the API has no build service configured.
+	Enabled       bool      `json:"enabled"`
+	Pending       bool      `json:"pending"`
+	Running       bool      `json:"running"`
+	LastRequested time.Time `json:"lastRequested"`
+	LastBuild     *Build    `json:"lastBuild"`
+}
+
+// Build is the outcome of a single build, which may have taken several attempts.
+type Build struct {
+	Started   time.Time `json:"started"`
+	Finished  time.Time `json:"finished"`
+	Attempts  int       `json:"attempts"`
+	Succeeded bool      `json:"succeeded"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// BackupResult describes a completed backup.
+type BackupResult struct {
+	Name     string   `json:"name"`
+	Manifest Manifest `json:"manifest"`
+}
+
+// Manifest describes the contents of a backup archive.
+type Manifest struct {
+	Version int            `json:"version"`
+	Created time.Time      `json:"created"`
+	Files   []ManifestFile `json:"files"`
+}
+
+// ManifestFile describes a file within a backup archive.
+type ManifestFile struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	SHA256  string `json:"sha256"`
+}
+
+// RestoreReport describes the changes made by a restore, or that would be made by a dry run.
+type RestoreReport struct {
+	Name   string  `json:"name"`
+	DryRun bool    `json:"dryRun"`
+	Posts  Changes `json:"posts"`
+	Likes  Changes `json:"likes"`
+	// Sanitized maps the IDs of posts to the markup stripped from their content when they were rendered.
+	Sanitized map[string]Report `json:"sanitized"`
+}
+
+// Changes lists the IDs of records created or updated by a restore, and of those skipped due to conflicts.
+type Changes struct {
+	Created   []string   `json:"created"`
+	Updated   []string   `json:"updated"`
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+// Conflict describes why a record couldn't be restored.
+type Conflict struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
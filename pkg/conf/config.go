@@ -9,14 +9,31 @@ import (
 )
 
 type Config struct {
-	GCloudProjectID       string `json:"gcloudProjectID"`
-	BuildServiceEndpoint  string `json:"buildServiceEndpoint"`
-	AllowedOriginHeader   string `json:"allowedOriginHeader"`
-	FirestoreDatabaseName string `json:"firestoreDatabaseName"`
-	BackupBucketName      string `json:"backupBucketName"`
-	APIUsername           string `json:"apiUsername"`
-	APIPassword           string `json:"apiPassword"`
-	TokenSecret           string `json:"tokenSecret"`
+	GCloudProjectID       string          `json:"gcloudProjectID"`
+	BuildServiceEndpoint  string          `json:"buildServiceEndpoint"`
+	BuildDelaySeconds     int             `json:"buildDelaySeconds"`
+	AllowedOriginHeader   string          `json:"allowedOriginHeader"`
+	FirestoreDatabaseName string          `json:"firestoreDatabaseName"`
+	BackupBucketName      string          `json:"backupBucketName"`
+	BackupIntervalHours   int             `json:"backupIntervalHours"`
+	APIUsername           string          `json:"apiUsername"`
+	APIPassword           string          `json:"apiPassword"`
+	TokenSecret           string          `json:"tokenSecret"`
+	StorageBackend        string          `json:"storageBackend"`
+	SQLitePath            string          `json:"sqlitePath"`
+	PreviewParagraphs     int             `json:"previewParagraphs"`
+	SanitizerPolicy       SanitizerPolicy `json:"sanitizerPolicy"`
+}
+
+// SanitizerPolicy lists the HTML allowed in post content. Any part of the policy left empty
+// falls back to a default allow-list.
+type SanitizerPolicy struct {
+	// Elements are the names of allowed elements.
+	Elements []string `json:"elements"`
+	// Attributes maps element names to their allowed attributes. Attributes under '*' are allowed on every element.
+	Attributes map[string][]string `json:"attributes"`
+	// URLSchemes are the schemes allowed in URL attributes, such as 'href' and 'src'.
+	URLSchemes []string `json:"urlSchemes"`
 }
 
 //go:embed config/*
@@ -46,6 +63,12 @@ func LoadConfig() (Config, error) {
 	if config.TokenSecret == "" {
 		config.TokenSecret = os.Getenv("TOKEN_SECRET")
 	}
+	if config.StorageBackend == "" {
+		config.StorageBackend = os.Getenv("STORAGE_BACKEND")
+	}
+	if config.SQLitePath == "" {
+		config.SQLitePath = os.Getenv("SQLITE_PATH")
+	}
 
 	return config, nil
 }
@@ -0,0 +1,84 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// DefaultTheme is the highlighting theme used when none is requested.
+const DefaultTheme = "github"
+
+// ErrUnknownTheme is returned when a highlighting theme doesn't exist.
+var ErrUnknownTheme = errors.New("unknown theme")
+
+// formatter emits highlighted code as spans with classes, so the theme is applied by a stylesheet.
+var formatter = chromahtml.New(chromahtml.WithClasses(true))
+
+// HighlightCSS returns the stylesheet for a highlighting theme.
+func HighlightCSS(theme string) ([]byte, error) {
+	style, ok := styles.Registry[theme]
+	if !ok {
+		return nil, fmt.Errorf("no theme '%s'; %w", theme, ErrUnknownTheme)
+	}
+	var css bytes.Buffer
+	if err := formatter.WriteCSS(&css, style); err != nil {
+		return nil, fmt.Errorf("failed to write css for theme '%s'; %w", theme, err)
+	}
+	return css.Bytes(), nil
+}
+
+// codeBlockRenderer highlights fenced code blocks tagged with a known language. Other code blocks
+// are rendered as usual.
+type codeBlockRenderer struct{}
+
+func (r codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r codeBlockRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.FencedCodeBlock)
+	var code bytes.Buffer
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		code.Write(line.Value(source))
+	}
+
+	language := n.Language(source)
+	if language != nil {
+		if lexer := lexers.Get(string(language)); lexer != nil {
+			tokens, err := chroma.Coalesce(lexer).Tokenise(nil, code.String())
+			if err != nil {
+				return ast.WalkStop, fmt.Errorf("failed to tokenise '%s' code; %w", language, err)
+			}
+			if err := formatter.Format(w, styles.Fallback, tokens); err != nil {
+				return ast.WalkStop, fmt.Errorf("failed to highlight '%s' code; %w", language, err)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+	}
+
+	// Matches goldmark's own rendering of code blocks
+	_, _ = w.WriteString("<pre><code")
+	if language != nil {
+		_, _ = w.WriteString(` class="language-`)
+		html.DefaultWriter.Write(w, language)
+		_, _ = w.WriteString(`"`)
+	}
+	_ = w.WriteByte('>')
+	html.DefaultWriter.RawWrite(w, code.Bytes())
+	_, _ = w.WriteString("</code></pre>\n")
+	return ast.WalkSkipChildren, nil
+}
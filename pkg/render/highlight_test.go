@@ -0,0 +1,56 @@
+package render
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+)
+
+func TestHighlight(t *testing.T) {
+	renderer := NewRenderer(conf.Config{})
+
+	tests := []struct {
+		name     string
+		content  string
+		contains []string
+	}{
+		{"go", "```go\nfunc main() {}\n```", []string{`<pre class="chroma">`, `<span class="kd">func</span>`, `<span class="nf">main</span>`}},
+		{"shell", "```sh\necho \"hi\" | wc -c\n```", []string{`<pre class="chroma">`, `<span class="nb">echo</span>`, `<span class="s2">&#34;hi&#34;</span>`}},
+		{"unknown language", "```nonsense\n<b>x</b>\n```", []string{`<pre><code class="language-nonsense">&lt;b&gt;x&lt;/b&gt;`}},
+		{"no language", "```\nx := 1\n```", []string{"<pre><code>x := 1\n</code></pre>"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := renderer.Render(test.content)
+			if err != nil {
+				t.Fatalf("failed to render; %s", err)
+			}
+			for _, s := range test.contains {
+				if !strings.Contains(result.HTML, s) {
+					t.Errorf("expected html to contain %q, got %q", s, result.HTML)
+				}
+			}
+			if len(result.Report) != 0 {
+				t.Errorf("expected nothing to be sanitized, got %+v", result.Report)
+			}
+		})
+	}
+}
+
+func TestHighlightCSS(t *testing.T) {
+	css, err := HighlightCSS(DefaultTheme)
+	if err != nil {
+		t.Fatalf("failed to get css; %s", err)
+	}
+	if !strings.Contains(string(css), ".chroma .kd {") {
+		t.Errorf("expected css for keyword class, got %q", css)
+	}
+
+	_, err = HighlightCSS("bogus")
+	if !errors.Is(err, ErrUnknownTheme) {
+		t.Errorf("expected unknown theme, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+// Package render converts the Markdown content of posts into HTML.
+package render
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/types"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// moreMarker separates the preview of a post from the rest of its content.
+const moreMarker = "<!-- more -->"
+
+// defaultPreviewParagraphs is the number of paragraphs in a preview, if the content has no marker.
+const defaultPreviewParagraphs = 2
+
+// Renderer renders Markdown as CommonMark, with GitHub Flavored Markdown tables, strikethrough,
+// autolinks and task lists, and footnotes. Fenced code blocks are highlighted, and rendered HTML is sanitized.
+type Renderer struct {
+	markdown          goldmark.Markdown
+	sanitizer         *Sanitizer
+	previewParagraphs int
+}
+
+// Result holds the HTML rendered from Markdown content, and a report of markup stripped from it.
+type Result struct {
+	HTML        string
+	PreviewHTML string
+	Report      Report
+}
+
+func NewRenderer(config conf.Config) *Renderer {
+	previewParagraphs := config.PreviewParagraphs
+	if previewParagraphs <= 0 {
+		previewParagraphs = defaultPreviewParagraphs
+	}
+
+	return &Renderer{
+		markdown: goldmark.New(
+			goldmark.WithExtensions(extension.GFM, extension.Footnote),
+			// Headings are given anchors, so the table of contents can link to them
+			goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+			// Raw HTML in content is kept, as posts embed media, then sanitized
+			goldmark.WithRendererOptions(
+				html.WithUnsafe(),
+				renderer.WithNodeRenderers(util.Prioritized(codeBlockRenderer{}, 100)),
+			),
+		),
+		sanitizer:         NewSanitizer(config.SanitizerPolicy),
+		previewParagraphs: previewParagraphs,
+	}
+}
+
+// Post renders a post's content into its HTML fields, replacing any HTML already set.
+// Returns a report of markup stripped from the content by the sanitizer.
+func (r *Renderer) Post(post types.Post) (types.Post, Report, error) {
+	result, err := r.Render(post.Content)
+	if err != nil {
+		return types.Post{}, nil, err
+	}
+	post.ContentHTML = result.HTML
+	post.ContentHTMLPreview = result.PreviewHTML
+	return post, result.Report, nil
+}
+
+// Render renders Markdown content as HTML, along with a preview. The preview holds the content
+// before a '<!-- more -->' marker, or the first paragraphs if there is no marker.
+func (r *Renderer) Render(content string) (Result, error) {
+	source := []byte(content)
+	doc := r.markdown.Parser().Parse(text.NewReader(source))
+
+	cut := r.previewCut(doc, source)
+	var full bytes.Buffer
+	if err := r.markdown.Renderer().Render(&full, source, doc); err != nil {
+		return Result{}, types.WrapErr(err, "failed to render content")
+	}
+
+	// Footnotes are kept in the preview, as paragraphs within it may reference them
+	for node := cut; node != nil; {
+		next := node.NextSibling()
+		if node.Kind() != east.KindFootnoteList {
+			doc.RemoveChild(doc, node)
+		}
+		node = next
+	}
+	var preview bytes.Buffer
+	if err := r.markdown.Renderer().Render(&preview, source, doc); err != nil {
+		return Result{}, types.WrapErr(err, "failed to render preview")
+	}
+
+	// The preview is part of the full content, so only the full content is reported on
+	fullHTML, report, err := r.sanitizer.Sanitize(full.String())
+	if err != nil {
+		return Result{}, types.WrapErr(err, "failed to sanitize content")
+	}
+	previewHTML, _, err := r.sanitizer.Sanitize(preview.String())
+	if err != nil {
+		return Result{}, types.WrapErr(err, "failed to sanitize preview")
+	}
+
+	return Result{HTML: fullHTML, PreviewHTML: previewHTML, Report: report}, nil
+}
+
+// previewCut returns the first top-level node to leave out of the preview, or nil if the preview
+// holds the whole document. A '<!-- more -->' marker is removed from the document.
+func (r *Renderer) previewCut(doc ast.Node, source []byte) ast.Node {
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		if isMoreMarker(node, source) {
+			cut := node.NextSibling()
+			doc.RemoveChild(doc, node)
+			return cut
+		}
+	}
+
+	paragraphs := 0
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		if node.Kind() != ast.KindParagraph {
+			continue
+		}
+		paragraphs++
+		if paragraphs == r.previewParagraphs {
+			return node.NextSibling()
+		}
+	}
+	return nil
+}
+
+func isMoreMarker(node ast.Node, source []byte) bool {
+	block, ok := node.(*ast.HTMLBlock)
+	if !ok {
+		return false
+	}
+	var raw strings.Builder
+	lines := block.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		raw.Write(segment.Value(source))
+	}
+	return strings.TrimSpace(raw.String()) == moreMarker
+}
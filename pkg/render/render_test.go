@@ -0,0 +1,112 @@
+package render
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+)
+
+func TestRender(t *testing.T) {
+	renderer := NewRenderer(conf.Config{})
+
+	tests := []struct {
+		name     string
+		content  string
+		contains []string
+	}{
+		{"heading", "# Title", []string{`<h1 id="title">Title</h1>`}},
+		{"emphasis", "*a* **b**", []string{"<em>a</em>", "<strong>b</strong>"}},
+		{"strikethrough", "~~gone~~", []string{"<del>gone</del>"}},
+		{"table", "| a | b |\n| - | - |\n| 1 | 2 |", []string{"<table>", "<th>a</th>", "<td>2</td>"}},
+		{"footnote", "Text[^1]\n\n[^1]: Note", []string{`href="#fn:1"`, `<li id="fn:1">`, "Note"}},
+		{"autolink", "See https://george.black", []string{`<a href="https://george.black">`}},
+		{"code", "```\nfunc main() {}\n```", []string{"<pre><code>func main() {}"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := renderer.Render(test.content)
+			if err != nil {
+				t.Fatalf("failed to render; %s", err)
+			}
+			for _, s := range test.contains {
+				if !strings.Contains(result.HTML, s) {
+					t.Errorf("expected html to contain %q, got %q", s, result.HTML)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderPreview(t *testing.T) {
+	renderer := NewRenderer(conf.Config{})
+
+	// ==================== Test case 1: Marker ====================
+	result, err := renderer.Render("First\n\n<!-- more -->\n\nSecond\n\nThird")
+	if err != nil {
+		t.Fatalf("failed to render; %s", err)
+	}
+	if result.PreviewHTML != "<p>First</p>\n" {
+		t.Errorf("expected preview of content before marker, got %q", result.PreviewHTML)
+	}
+	if strings.Contains(result.HTML, moreMarker) || !strings.Contains(result.HTML, "<p>Third</p>") {
+		t.Errorf("expected full content without marker, got %q", result.HTML)
+	}
+
+	// ==================== Test case 2: No marker ====================
+	result, err = renderer.Render("# Title\n\nFirst\n\nSecond\n\nThird")
+	if err != nil {
+		t.Fatalf("failed to render; %s", err)
+	}
+	if result.PreviewHTML != "<h1 id=\"title\">Title</h1>\n<p>First</p>\n<p>Second</p>\n" {
+		t.Errorf("expected preview of first two paragraphs, got %q", result.PreviewHTML)
+	}
+
+	// ==================== Test case 3: Short content ====================
+	result, err = renderer.Render("Only")
+	if err != nil {
+		t.Fatalf("failed to render; %s", err)
+	}
+	if result.PreviewHTML != result.HTML {
+		t.Errorf("expected preview of whole content %q, got %q", result.HTML, result.PreviewHTML)
+	}
+
+	// ==================== Test case 4: Footnotes referenced by preview ====================
+	result, err = renderer.Render("First[^1]\n\n<!-- more -->\n\nSecond\n\n[^1]: Note")
+	if err != nil {
+		t.Fatalf("failed to render; %s", err)
+	}
+	if !strings.Contains(result.PreviewHTML, `<li id="fn:1">`) || strings.Contains(result.PreviewHTML, "Second") {
+		t.Errorf("expected preview with footnotes, got %q", result.PreviewHTML)
+	}
+
+	// ==================== Test case 5: Configured paragraphs ====================
+	result, err = NewRenderer(conf.Config{PreviewParagraphs: 1}).Render("First\n\nSecond")
+	if err != nil {
+		t.Fatalf("failed to render; %s", err)
+	}
+	if result.PreviewHTML != "<p>First</p>\n" {
+		t.Errorf("expected preview of first paragraph, got %q", result.PreviewHTML)
+	}
+}
+
+func TestRenderSanitizes(t *testing.T) {
+	renderer := NewRenderer(conf.Config{})
+
+	result, err := renderer.Render("- [x] done\n\n<script>alert(1)</script>\n\n<video src=\"/a.mp4\"></video>")
+	if err != nil {
+		t.Fatalf("failed to render; %s", err)
+	}
+	if strings.Contains(result.HTML, "script") || strings.Contains(result.PreviewHTML, "script") {
+		t.Errorf("expected script to be stripped, got %q and %q", result.HTML, result.PreviewHTML)
+	}
+	if !strings.Contains(result.HTML, `<input checked="" disabled="" type="checkbox"/>`) {
+		t.Errorf("expected task list checkbox to be kept, got %q", result.HTML)
+	}
+	expected := Report{{Element: "script", Count: 1}, {Element: "video", Count: 1}}
+	if !slices.Equal(result.Report, expected) {
+		t.Errorf("expected report %+v, got %+v", expected, result.Report)
+	}
+}
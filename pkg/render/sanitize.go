@@ -0,0 +1,193 @@
+package render
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/types"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// defaultElements are allowed if the policy doesn't list any elements.
+var defaultElements = []string{
+	"a", "abbr", "b", "blockquote", "br", "code", "dd", "del", "details", "div", "dl", "dt", "em",
+	"figcaption", "figure", "h1", "h2", "h3", "h4", "h5", "h6", "hr", "i", "img", "input", "ins", "kbd",
+	"li", "mark", "ol", "p", "pre", "q", "s", "small", "span", "strong", "sub", "summary", "sup",
+	"table", "tbody", "td", "tfoot", "th", "thead", "tr", "u", "ul",
+}
+
+// defaultAttributes are allowed if the policy doesn't list any attributes.
+// Attributes listed under '*' are allowed on every element.
+var defaultAttributes = map[string][]string{
+	"*":          {"id", "class", "title", "lang", "dir", "role"},
+	"a":          {"href"},
+	"blockquote": {"cite"},
+	"del":        {"cite"},
+	"img":        {"src", "alt", "width", "height", "loading"},
+	"input":      {"type", "checked", "disabled"},
+	"ins":        {"cite"},
+	"ol":         {"start"},
+	"q":          {"cite"},
+	"td":         {"align", "colspan", "rowspan"},
+	"th":         {"align", "colspan", "rowspan"},
+}
+
+// defaultURLSchemes are allowed if the policy doesn't list any schemes. Relative URLs are always allowed.
+var defaultURLSchemes = []string{"http", "https", "mailto"}
+
+// urlAttributes hold URLs, so their schemes are checked against the policy.
+var urlAttributes = []string{"href", "src", "cite", "poster"}
+
+// droppedElements are removed along with their content if not allowed. Other elements that
+// aren't allowed are replaced by their content.
+var droppedElements = []string{
+	"script", "style", "template", "noscript", "iframe", "object", "embed", "applet", "frame", "frameset",
+	"textarea", "select", "title", "xmp", "plaintext", "noembed", "noframes", "svg", "math",
+}
+
+// Removal describes markup stripped by the sanitizer. If Attribute is empty, the element itself was removed.
+type Removal struct {
+	Element   string `json:"element"`
+	Attribute string `json:"attribute,omitempty"`
+	Count     int    `json:"count"`
+}
+
+// Report lists the markup stripped by the sanitizer, in the order it was first found.
+type Report []Removal
+
+func (r *Report) add(element string, attribute string) {
+	for i := range *r {
+		if (*r)[i].Element == element && (*r)[i].Attribute == attribute {
+			(*r)[i].Count++
+			return
+		}
+	}
+	*r = append(*r, Removal{Element: element, Attribute: attribute, Count: 1})
+}
+
+// Sanitizer strips any HTML elements, attributes, and URL schemes not allowed by its policy.
+// Comments are always stripped.
+type Sanitizer struct {
+	elements   map[string]bool
+	attributes map[string]map[string]bool
+	schemes    map[string]bool
+}
+
+// NewSanitizer creates a sanitizer for the policy, using defaults for any part of the policy left empty.
+func NewSanitizer(policy conf.SanitizerPolicy) *Sanitizer {
+	elements := policy.Elements
+	if len(elements) == 0 {
+		elements = defaultElements
+	}
+	attributes := policy.Attributes
+	if len(attributes) == 0 {
+		attributes = defaultAttributes
+	}
+	schemes := policy.URLSchemes
+	if len(schemes) == 0 {
+		schemes = defaultURLSchemes
+	}
+
+	s := &Sanitizer{
+		elements:   make(map[string]bool),
+		attributes: make(map[string]map[string]bool),
+		schemes:    make(map[string]bool),
+	}
+	for _, element := range elements {
+		s.elements[strings.ToLower(element)] = true
+	}
+	for element, names := range attributes {
+		s.attributes[strings.ToLower(element)] = make(map[string]bool)
+		for _, name := range names {
+			s.attributes[strings.ToLower(element)][strings.ToLower(name)] = true
+		}
+	}
+	for _, scheme := range schemes {
+		s.schemes[strings.ToLower(scheme)] = true
+	}
+	return s
+}
+
+// Sanitize strips disallowed markup from an HTML fragment, returning the sanitized fragment
+// and a report of what was stripped.
+func (s *Sanitizer) Sanitize(fragment string) (string, Report, error) {
+	context := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), context)
+	if err != nil {
+		return "", nil, types.WrapErr(err, "failed to parse html")
+	}
+
+	root := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	for _, node := range nodes {
+		root.AppendChild(node)
+	}
+	report := make(Report, 0)
+	s.sanitizeChildren(root, &report)
+
+	var b strings.Builder
+	for node := root.FirstChild; node != nil; node = node.NextSibling {
+		if err := html.Render(&b, node); err != nil {
+			return "", nil, types.WrapErr(err, "failed to render html")
+		}
+	}
+	return b.String(), report, nil
+}
+
+func (s *Sanitizer) sanitizeChildren(parent *html.Node, report *Report) {
+	for node := parent.FirstChild; node != nil; {
+		next := node.NextSibling
+		switch node.Type {
+		case html.TextNode:
+		case html.ElementNode:
+			if s.elements[node.Data] {
+				s.sanitizeAttributes(node, report)
+				s.sanitizeChildren(node, report)
+				break
+			}
+			report.add(node.Data, "")
+			if !slices.Contains(droppedElements, node.Data) {
+				// Keep the element's content in its place
+				s.sanitizeChildren(node, report)
+				for child := node.FirstChild; child != nil; child = node.FirstChild {
+					node.RemoveChild(child)
+					parent.InsertBefore(child, node)
+				}
+			}
+			parent.RemoveChild(node)
+		default:
+			parent.RemoveChild(node)
+		}
+		node = next
+	}
+}
+
+func (s *Sanitizer) sanitizeAttributes(node *html.Node, report *Report) {
+	attrs := make([]html.Attribute, 0, len(node.Attr))
+	for _, attr := range node.Attr {
+		name := attr.Key
+		if attr.Namespace != "" {
+			name = attr.Namespace + ":" + attr.Key
+		}
+		allowed := s.attributes["*"][name] || s.attributes[node.Data][name]
+		if allowed && slices.Contains(urlAttributes, name) {
+			allowed = s.allowedURL(attr.Val)
+		}
+		if !allowed {
+			report.add(node.Data, name)
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+	node.Attr = attrs
+}
+
+func (s *Sanitizer) allowedURL(value string) bool {
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || s.schemes[strings.ToLower(u.Scheme)]
+}
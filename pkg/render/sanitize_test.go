@@ -0,0 +1,110 @@
+package render
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+)
+
+func TestSanitize(t *testing.T) {
+	sanitizer := NewSanitizer(conf.SanitizerPolicy{})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		report   Report
+	}{
+		{
+			name:     "allowed markup",
+			input:    `<p>Hi <a href="https://george.black" class="x">there</a></p>`,
+			expected: `<p>Hi <a href="https://george.black" class="x">there</a></p>`,
+			report:   Report{},
+		},
+		{
+			name:     "relative url",
+			input:    `<a href="/posts#top">top</a>`,
+			expected: `<a href="/posts#top">top</a>`,
+			report:   Report{},
+		},
+		{
+			name:     "script",
+			input:    `<p>a</p><script>alert(1)</script><script>alert(2)</script>`,
+			expected: `<p>a</p>`,
+			report:   Report{{Element: "script", Count: 2}},
+		},
+		{
+			name:     "unknown element keeps content",
+			input:    `<p><blink>hello <b>there</b></blink></p>`,
+			expected: `<p>hello <b>there</b></p>`,
+			report:   Report{{Element: "blink", Count: 1}},
+		},
+		{
+			name:     "event handler",
+			input:    `<img src="/a.png" onerror="alert(1)">`,
+			expected: `<img src="/a.png"/>`,
+			report:   Report{{Element: "img", Attribute: "onerror", Count: 1}},
+		},
+		{
+			name:     "javascript url",
+			input:    `<a href="javascript:alert(1)">x</a><a href="jav&#x61;script:alert(1)">y</a>`,
+			expected: `<a>x</a><a>y</a>`,
+			report:   Report{{Element: "a", Attribute: "href", Count: 2}},
+		},
+		{
+			name:     "style attribute",
+			input:    `<p style="position:fixed">x</p>`,
+			expected: `<p>x</p>`,
+			report:   Report{{Element: "p", Attribute: "style", Count: 1}},
+		},
+		{
+			name:     "comments",
+			input:    `<p>a<!-- note --></p>`,
+			expected: `<p>a</p>`,
+			report:   Report{},
+		},
+		{
+			name:     "nested disallowed",
+			input:    `<div><iframe src="https://evil.example"></iframe><form><p>in form</p></form></div>`,
+			expected: `<div><p>in form</p></div>`,
+			report:   Report{{Element: "iframe", Count: 1}, {Element: "form", Count: 1}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, report, err := sanitizer.Sanitize(test.input)
+			if err != nil {
+				t.Fatalf("failed to sanitize; %s", err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+			if !slices.Equal(report, test.report) {
+				t.Errorf("expected report %+v, got %+v", test.report, report)
+			}
+		})
+	}
+}
+
+func TestSanitizePolicy(t *testing.T) {
+	sanitizer := NewSanitizer(conf.SanitizerPolicy{
+		Elements:   []string{"p", "iframe"},
+		Attributes: map[string][]string{"iframe": {"src"}},
+		URLSchemes: []string{"https"},
+	})
+
+	actual, report, err := sanitizer.Sanitize(`<p class="x">a</p><iframe src="https://www.youtube.com/embed/x"></iframe><iframe src="http://a"></iframe><em>b</em>`)
+	if err != nil {
+		t.Fatalf("failed to sanitize; %s", err)
+	}
+	expected := `<p>a</p><iframe src="https://www.youtube.com/embed/x"></iframe><iframe></iframe>b`
+	if actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	expectedReport := Report{{Element: "p", Attribute: "class", Count: 1}, {Element: "iframe", Attribute: "src", Count: 1}, {Element: "em", Count: 1}}
+	if !slices.Equal(report, expectedReport) {
+		t.Errorf("expected report %+v, got %+v", expectedReport, report)
+	}
+}
@@ -0,0 +1,22 @@
+package render
+
+import "testing"
+
+func TestText(t *testing.T) {
+	cases := map[string]string{
+		"":                                "",
+		"Just text":                       "Just text",
+		"# Title\n\nSome *emphasis* here": "Title\nSome emphasis here",
+		"A [link](https://example.com) and <https://example.org>": "A link and https://example.org",
+		"Line one\nline two":                           "Line one line two",
+		"Before\n\n```go\nfmt.Println()\n```\n\nAfter": "Before\n\nAfter",
+		"<div>raw html</div>\n\nText":                  "Text",
+		"- one\n- two":                                 "one\n\ntwo",
+	}
+
+	for content, expected := range cases {
+		if actual := Text(content); actual != expected {
+			t.Errorf("expected text %q for content %q, got %q", expected, content, actual)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package render
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Heading is an entry in a post's table of contents.
+type Heading struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor"`
+}
+
+// TOC returns the headings of Markdown content in document order, with the anchors assigned
+// to them when the content is rendered.
+func (r *Renderer) TOC(content string) []Heading {
+	source := []byte(content)
+	doc := r.markdown.Parser().Parse(text.NewReader(source))
+
+	headings := make([]Heading, 0)
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := node.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		var anchor string
+		if id, ok := heading.AttributeString("id"); ok {
+			anchor = string(id.([]byte))
+		}
+		headings = append(headings, Heading{
+			Level:  heading.Level,
+			Text:   plainText(heading, source),
+			Anchor: anchor,
+		})
+		return ast.WalkSkipChildren, nil
+	})
+	return headings
+}
@@ -0,0 +1,43 @@
+package render
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+)
+
+func TestTOC(t *testing.T) {
+	renderer := NewRenderer(conf.Config{})
+	content := "# Intro\n\nText\n\n## Setting *up* `go`\n\n## Intro\n\n> ### Quoted\n\nSetext\n------\n\n## 日本語\n"
+
+	expected := []Heading{
+		{Level: 1, Text: "Intro", Anchor: "intro"},
+		{Level: 2, Text: "Setting up go", Anchor: "setting-up-go"},
+		{Level: 2, Text: "Intro", Anchor: "intro-1"},
+		{Level: 3, Text: "Quoted", Anchor: "quoted"},
+		{Level: 2, Text: "Setext", Anchor: "setext"},
+		{Level: 2, Text: "日本語", Anchor: "heading"},
+	}
+	actual := renderer.TOC(content)
+	if !slices.Equal(actual, expected) {
+		t.Errorf("expected toc %+v, got %+v", expected, actual)
+	}
+
+	// Anchors match the ids of rendered headings
+	result, err := renderer.Render(content)
+	if err != nil {
+		t.Fatalf("failed to render; %s", err)
+	}
+	for _, heading := range actual {
+		if !strings.Contains(result.HTML, `id="`+heading.Anchor+`"`) {
+			t.Errorf("expected html to contain anchor %s, got %q", heading.Anchor, result.HTML)
+		}
+	}
+
+	// Content without headings
+	if toc := renderer.TOC("Just text"); toc == nil || len(toc) != 0 {
+		t.Errorf("expected empty toc, got %+v", toc)
+	}
+}
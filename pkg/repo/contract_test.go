@@ -0,0 +1,51 @@
+package repo_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/repo"
+	"github.com/georgemblack/web-api/pkg/repo/repotest"
+)
+
+func TestMemoryContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repo.Store {
+		return repo.NewMemoryService()
+	})
+}
+
+func TestSQLiteContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repo.Store {
+		service, err := repo.NewSQLiteService(filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatalf("failed to create sqlite service; %s", err)
+		}
+		t.Cleanup(service.Close)
+		return service
+	})
+}
+
+// TestFirestoreContract runs against the Firestore emulator, i.e.
+//
+//	gcloud emulators firestore start --host-port=localhost:8080
+//	FIRESTORE_EMULATOR_HOST=localhost:8080 go test ./pkg/repo
+func TestFirestoreContract(t *testing.T) {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST not set")
+	}
+	config, err := conf.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config; %s", err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repo.Store {
+		service, err := repo.NewFirestoreService(config)
+		if err != nil {
+			t.Fatalf("failed to create firestore service; %s", err)
+		}
+		t.Cleanup(service.Close)
+		return service
+	})
+}
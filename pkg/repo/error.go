@@ -0,0 +1,34 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNotFound is returned when a requested document does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a write would clash with an existing document.
+	ErrConflict = errors.New("conflict")
+	// ErrInvalid is returned when a request is rejected as malformed.
+	ErrInvalid = errors.New("invalid")
+	// ErrPreconditionFailed is returned when a document has changed since the version a write was based on.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// wrapErr wraps an error returned by the Firestore client, tagging it with the matching
+// repo error (if any) so callers can inspect it with errors.Is.
+func wrapErr(err error, message string) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return fmt.Errorf("%s; %w; %w", message, ErrNotFound, err)
+	case codes.AlreadyExists:
+		return fmt.Errorf("%s; %w; %w", message, ErrConflict, err)
+	case codes.InvalidArgument:
+		return fmt.Errorf("%s; %w; %w", message, ErrInvalid, err)
+	}
+	return fmt.Errorf("%s; %w", message, err)
+}
@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWrapErr(t *testing.T) {
+	cases := []struct {
+		code     codes.Code
+		expected error
+	}{
+		{codes.NotFound, ErrNotFound},
+		{codes.AlreadyExists, ErrConflict},
+		{codes.InvalidArgument, ErrInvalid},
+	}
+
+	for _, tc := range cases {
+		original := status.Error(tc.code, "ope")
+		err := wrapErr(original, "failed")
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("expected %s to wrap %v", tc.code, tc.expected)
+		}
+		if !errors.Is(err, original) {
+			t.Errorf("expected %s to wrap original error", tc.code)
+		}
+	}
+
+	// Other codes should not be tagged with a repo error, including aborted transactions, which lost
+	// a race with a concurrent transaction rather than clashing with an existing document
+	for _, code := range []codes.Code{codes.Unavailable, codes.Aborted} {
+		err := wrapErr(status.Error(code, "ope"), "failed")
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) || errors.Is(err, ErrInvalid) {
+			t.Errorf("expected %s error to be untagged, got %v", code, err)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package repo
 import (
 	"context"
 	"fmt"
+	"maps"
+	"os"
+	"slices"
 	"time"
 
 	firestore "cloud.google.com/go/firestore/apiv1"
@@ -10,24 +13,64 @@ import (
 	"github.com/georgemblack/web-api/pkg/conf"
 	"github.com/georgemblack/web-api/pkg/types"
 	"github.com/google/uuid"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 type Firestore struct {
-	client *firestore.Client
-	config conf.Config
+	client  *firestore.Client
+	config  conf.Config
+	backoff backoff
+
+	// queryIterator runs queries, and is replaced in tests to simulate failures
+	queryIterator func(ctx context.Context, req *firestorepb.RunQueryRequest) (documentIterator, error)
 }
 
 func NewFirestoreService(config conf.Config) (*Firestore, error) {
 	ctx := context.Background()
-	client, err := firestore.NewClient(ctx)
+	client, err := firestore.NewClient(ctx, emulatorOptions()...)
 	if err != nil {
 		return &Firestore{}, types.WrapErr(err, "failed to create firestore client")
 	}
 
-	return &Firestore{
-		client: client,
-		config: config,
-	}, nil
+	f := &Firestore{
+		client:  client,
+		config:  config,
+		backoff: defaultBackoff,
+	}
+	f.queryIterator = f.newQueryIterator
+	return f, nil
+}
+
+// emulatorOptions returns client options to connect to the Firestore emulator, if the
+// 'FIRESTORE_EMULATOR_HOST' env var is set.
+func emulatorOptions() []option.ClientOption {
+	host := os.Getenv("FIRESTORE_EMULATOR_HOST")
+	if host == "" {
+		return nil
+	}
+	return []option.ClientOption{
+		option.WithEndpoint(host),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		option.WithGRPCDialOption(grpc.WithPerRPCCredentials(emulatorCredentials{})),
+	}
+}
+
+// emulatorCredentials authenticate with the emulator as an admin, bypassing security rules.
+type emulatorCredentials struct{}
+
+func (emulatorCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer owner"}, nil
+}
+
+func (emulatorCredentials) RequireTransportSecurity() bool {
+	return false
 }
 
 func (f *Firestore) GetLike(id string) (types.Like, error) {
@@ -35,33 +78,48 @@ func (f *Firestore) GetLike(id string) (types.Like, error) {
 	req := firestorepb.GetDocumentRequest{
 		Name: fmt.Sprintf("projects/%s/databases/%s/documents/web-likes/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, id),
 	}
-	doc, err := f.client.GetDocument(ctx, &req)
+	var doc *firestorepb.Document
+	err := retry(ctx, f.backoff, func() error {
+		var err error
+		doc, err = f.client.GetDocument(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return types.Like{}, types.WrapErr(err, "failed to get like")
+		return types.Like{}, wrapErr(err, "failed to get like")
 	}
 
 	return docToLike(doc), nil
 }
 
 func (f *Firestore) GetLikes() ([]types.Like, error) {
+	likes, _, err := f.GetLikesPage(Page{})
+	return likes, err
+}
+
+func (f *Firestore) GetLikesPage(page Page) ([]types.Like, string, error) {
 	ctx := context.Background()
-	req := firestorepb.ListDocumentsRequest{
-		Parent:       fmt.Sprintf("projects/%s/databases/%s/documents", f.config.GCloudProjectID, f.config.FirestoreDatabaseName),
-		CollectionId: "web-likes",
-		OrderBy:      "timestamp desc",
+	query := &firestorepb.StructuredQuery{
+		From: []*firestorepb.StructuredQuery_CollectionSelector{{CollectionId: "web-likes"}},
+		OrderBy: []*firestorepb.StructuredQuery_Order{
+			order("timestamp", firestorepb.StructuredQuery_DESCENDING),
+			order("__name__", firestorepb.StructuredQuery_DESCENDING),
+		},
+	}
+	if err := f.paginate(query, "web-likes", page); err != nil {
+		return nil, "", err
+	}
+	docs, err := f.runQuery(ctx, query, nil)
+	if err != nil {
+		return nil, "", types.WrapErr(err, "failed to get likes")
 	}
-	iter := f.client.ListDocuments(ctx, &req)
 
-	likes := make([]types.Like, 0)
-	for {
-		doc, err := iter.Next()
-		if err != nil {
-			break
-		}
-		likes = append(likes, docToLike(doc))
+	docs, next := pageResults(docs, "timestamp", page)
+	likes := make([]types.Like, len(docs))
+	for i, doc := range docs {
+		likes[i] = docToLike(doc)
 	}
 
-	return likes, nil
+	return likes, next, nil
 }
 
 func (f *Firestore) AddLike(like types.Like) (string, error) {
@@ -75,20 +133,40 @@ func (f *Firestore) AddLike(like types.Like) (string, error) {
 	}
 	_, err := f.client.CreateDocument(ctx, &req)
 	if err != nil {
-		return "", types.WrapErr(err, "failed to create like")
+		return "", wrapErr(err, "failed to create like")
 	}
 
 	return id, nil
 }
 
+func (f *Firestore) PutLike(like types.Like) error {
+	if like.ID == "" {
+		return fmt.Errorf("like has no id; %w", ErrInvalid)
+	}
+	ctx := context.Background()
+	doc := likeToDoc(like)
+	doc.Name = fmt.Sprintf("projects/%s/databases/%s/documents/web-likes/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, like.ID)
+
+	// Without an update mask or precondition, the document is created or replaced
+	_, err := f.client.UpdateDocument(ctx, &firestorepb.UpdateDocumentRequest{Document: doc})
+	if err != nil {
+		return wrapErr(err, "failed to put like")
+	}
+
+	return nil
+}
+
 func (f *Firestore) DeleteLike(id string) error {
 	ctx := context.Background()
 	req := firestorepb.DeleteDocumentRequest{
 		Name: fmt.Sprintf("projects/%s/databases/%s/documents/web-likes/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, id),
+		CurrentDocument: &firestorepb.Precondition{
+			ConditionType: &firestorepb.Precondition_Exists{Exists: true},
+		},
 	}
 	err := f.client.DeleteDocument(ctx, &req)
 	if err != nil {
-		return types.WrapErr(err, "failed to delete like")
+		return wrapErr(err, "failed to delete like")
 	}
 
 	return nil
@@ -99,9 +177,14 @@ func (f *Firestore) GetPost(id string) (types.Post, error) {
 	req := firestorepb.GetDocumentRequest{
 		Name: fmt.Sprintf("projects/%s/databases/%s/documents/web-posts/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, id),
 	}
-	doc, err := f.client.GetDocument(ctx, &req)
+	var doc *firestorepb.Document
+	err := retry(ctx, f.backoff, func() error {
+		var err error
+		doc, err = f.client.GetDocument(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return types.Post{}, types.WrapErr(err, "failed to get post")
+		return types.Post{}, wrapErr(err, "failed to get post")
 	}
 
 	// Convert tags from firestore array to string array
@@ -114,111 +197,496 @@ func (f *Firestore) GetPost(id string) (types.Post, error) {
 	return docToPost(doc), nil
 }
 
-type PostFilters struct {
-	Listed    *bool
-	Published *bool
+func (f *Firestore) GetPosts(filters PostFilters) ([]types.Post, error) {
+	posts, _, err := f.GetPostsPage(filters, Page{})
+	return posts, err
 }
 
-func (f *Firestore) GetPosts(filters PostFilters) ([]types.Post, error) {
+// GetPostsPage evaluates the filters as part of the Firestore query.
+func (f *Firestore) GetPostsPage(filters PostFilters, page Page) ([]types.Post, string, error) {
 	ctx := context.Background()
-	req := firestorepb.ListDocumentsRequest{
-		Parent:       fmt.Sprintf("projects/%s/databases/%s/documents", f.config.GCloudProjectID, f.config.FirestoreDatabaseName),
-		CollectionId: "web-posts",
-		OrderBy:      "published desc",
+	query := &firestorepb.StructuredQuery{
+		From:  []*firestorepb.StructuredQuery_CollectionSelector{{CollectionId: "web-posts"}},
+		Where: postFilter(filters, time.Now()),
+		OrderBy: []*firestorepb.StructuredQuery_Order{
+			order("published", firestorepb.StructuredQuery_DESCENDING),
+			order("__name__", firestorepb.StructuredQuery_DESCENDING),
+		},
+	}
+	if err := f.paginate(query, "web-posts", page); err != nil {
+		return nil, "", err
+	}
+	docs, err := f.runQuery(ctx, query, nil)
+	if err != nil {
+		return nil, "", types.WrapErr(err, "failed to get posts")
+	}
+
+	docs, next := pageResults(docs, "published", page)
+	posts := make([]types.Post, len(docs))
+	for i, doc := range docs {
+		posts[i] = docToPost(doc)
+	}
+
+	return posts, next, nil
+}
+
+func (f *Firestore) GetPostBySlug(slug string, filters PostFilters) (types.Post, error) {
+	ctx := context.Background()
+	query := &firestorepb.StructuredQuery{
+		From:  []*firestorepb.StructuredQuery_CollectionSelector{{CollectionId: "web-posts"}},
+		Where: postFilter(filters, time.Now(), fieldFilter("slug", firestorepb.StructuredQuery_FieldFilter_EQUAL, stringValue(slug))),
+		Limit: wrapperspb.Int32(1),
+	}
+	docs, err := f.runQuery(ctx, query, nil)
+	if err != nil {
+		return types.Post{}, types.WrapErr(err, "failed to get post by slug")
+	}
+	if len(docs) > 0 {
+		return docToPost(docs[0]), nil
 	}
-	iter := f.client.ListDocuments(ctx, &req)
 
-	posts := make([]types.Post, 0)
-	for {
-		doc, err := iter.Next()
+	return types.Post{}, fmt.Errorf("no post with slug '%s'; %w", slug, ErrNotFound)
+}
+
+func (f *Firestore) AddPost(post types.Post) (string, error) {
+	ctx := context.Background()
+	post, err := preparePost(post)
+	if err != nil {
+		return "", err
+	}
+
+	id := uuid.New().String()
+	doc := postToDoc(post)
+	doc.Name = fmt.Sprintf("projects/%s/databases/%s/documents/web-posts/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, id)
+
+	_, err = f.runTransaction(ctx, func(tx []byte) ([]*firestorepb.Write, error) {
+		taken, err := f.slugTaken(ctx, tx, post.Slug, id)
 		if err != nil {
-			break
+			return nil, err
 		}
-		post := docToPost(doc)
+		if taken {
+			return nil, fmt.Errorf("slug '%s' already in use; %w", post.Slug, ErrConflict)
+		}
+		return []*firestorepb.Write{{
+			Operation: &firestorepb.Write_Update{Update: doc},
+			CurrentDocument: &firestorepb.Precondition{
+				ConditionType: &firestorepb.Precondition_Exists{Exists: false},
+			},
+		}}, nil
+	})
+	if err != nil {
+		return "", types.WrapErr(err, "failed to create post")
+	}
 
-		// 'Listed' filter verifies the post is marked as listed
-		if filters.Listed != nil {
-			if *filters.Listed && !post.Listed {
-				continue
-			}
-			if !*filters.Listed && post.Listed {
-				continue
-			}
+	return id, nil
+}
+
+func (f *Firestore) PutPost(post types.Post) error {
+	if post.ID == "" {
+		return fmt.Errorf("post has no id; %w", ErrInvalid)
+	}
+	ctx := context.Background()
+	post, err := preparePost(post)
+	if err != nil {
+		return err
+	}
+
+	doc := postToDoc(post)
+	doc.Name = fmt.Sprintf("projects/%s/databases/%s/documents/web-posts/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, post.ID)
+
+	_, err = f.runTransaction(ctx, func(tx []byte) ([]*firestorepb.Write, error) {
+		writes := []*firestorepb.Write{{Operation: &firestorepb.Write_Update{Update: doc}}}
+		current, err := f.client.GetDocument(ctx, &firestorepb.GetDocumentRequest{
+			Name:                doc.Name,
+			ConsistencySelector: &firestorepb.GetDocumentRequest_Transaction{Transaction: tx},
+		})
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return nil, wrapErr(err, "failed to get post")
+		default:
+			writes = append(writes, revisionWrite(current))
 		}
 
-		// 'Published' filter checks:
-		//	1. Whether a post is a draft
-		//	2. Whether the post's publsihed date is in the future
-		if filters.Published != nil {
-			if *filters.Published && (post.Draft || post.Published.After(time.Now())) {
-				continue
-			}
-			if !*filters.Published && (!post.Draft && post.Published.Before(time.Now())) {
-				continue
-			}
+		taken, err := f.slugTaken(ctx, tx, post.Slug, post.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, fmt.Errorf("slug '%s' already in use; %w", post.Slug, ErrConflict)
 		}
-		posts = append(posts, docToPost(doc))
+		return writes, nil
+	})
+	if err != nil {
+		return types.WrapErr(err, "failed to put post")
 	}
 
-	return posts, nil
+	return nil
 }
 
-func (f *Firestore) AddPost(post types.Post) (string, error) {
+func (f *Firestore) UpdatePost(post types.Post) (time.Time, error) {
 	ctx := context.Background()
-	id := uuid.New().String()
-	req := firestorepb.CreateDocumentRequest{
-		Parent:       fmt.Sprintf("projects/%s/databases/%s/documents", f.config.GCloudProjectID, f.config.FirestoreDatabaseName),
-		CollectionId: "web-posts",
-		DocumentId:   id,
-		Document:     postToDoc(post),
+	post, err := preparePost(post)
+	if err != nil {
+		return time.Time{}, err
 	}
-	for i, v := range post.Tags {
-		req.Document.Fields["tags"].GetArrayValue().Values[i] = &firestorepb.Value{ValueType: &firestorepb.Value_StringValue{StringValue: v}}
+
+	doc := postToDoc(post)
+	doc.Name = fmt.Sprintf("projects/%s/databases/%s/documents/web-posts/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, post.ID)
+
+	precondition := &firestorepb.Precondition{ConditionType: &firestorepb.Precondition_Exists{Exists: true}}
+	if !post.Updated.IsZero() {
+		precondition = &firestorepb.Precondition{ConditionType: &firestorepb.Precondition_UpdateTime{UpdateTime: timestamppb.New(post.Updated)}}
 	}
-	_, err := f.client.CreateDocument(ctx, &req)
+
+	resp, err := f.runTransaction(ctx, func(tx []byte) ([]*firestorepb.Write, error) {
+		current, err := f.client.GetDocument(ctx, &firestorepb.GetDocumentRequest{
+			Name:                doc.Name,
+			ConsistencySelector: &firestorepb.GetDocumentRequest_Transaction{Transaction: tx},
+		})
+		if err != nil {
+			return nil, wrapErr(err, "failed to get post")
+		}
+		taken, err := f.slugTaken(ctx, tx, post.Slug, post.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, fmt.Errorf("slug '%s' already in use; %w", post.Slug, ErrConflict)
+		}
+		return []*firestorepb.Write{{
+			Operation:       &firestorepb.Write_Update{Update: doc},
+			CurrentDocument: precondition,
+		}, revisionWrite(current)}, nil
+	})
 	if err != nil {
-		return "", types.WrapErr(err, "failed to create post")
+		// A stale update time is reported as a failed precondition on commit
+		if !post.Updated.IsZero() && status.Code(err) == codes.FailedPrecondition {
+			return time.Time{}, fmt.Errorf("post '%s' has changed; %w; %w", post.ID, ErrPreconditionFailed, err)
+		}
+		return time.Time{}, types.WrapErr(err, "failed to update post")
 	}
 
-	return id, nil
+	return resp.WriteResults[0].GetUpdateTime().AsTime(), nil
 }
 
-func (f *Firestore) UpdatePost(post types.Post) error {
-	ctx := context.Background()
+// slugTaken reports whether a post other than the one with the given ID uses the slug.
+// The query runs within the transaction, so a concurrent write of the same slug causes
+// the transaction to fail rather than both writes succeeding.
+func (f *Firestore) slugTaken(ctx context.Context, tx []byte, slug string, postID string) (bool, error) {
+	query := &firestorepb.StructuredQuery{
+		From:  []*firestorepb.StructuredQuery_CollectionSelector{{CollectionId: "web-posts"}},
+		Where: fieldFilter("slug", firestorepb.StructuredQuery_FieldFilter_EQUAL, stringValue(slug)),
+	}
+	docs, err := f.runQuery(ctx, query, tx)
+	if err != nil {
+		return false, err
+	}
 
-	// Convert tags from string array to firestore array
-	tags := make([]*firestorepb.Value, len(post.Tags))
-	for i, v := range post.Tags {
-		tags[i] = &firestorepb.Value{ValueType: &firestorepb.Value_StringValue{StringValue: v}}
+	for _, doc := range docs {
+		if id(doc) != postID {
+			return true, nil
+		}
 	}
+	return false, nil
+}
 
-	req := firestorepb.UpdateDocumentRequest{
-		Document: &firestorepb.Document{
-			Name:   fmt.Sprintf("projects/%s/databases/%s/documents/web-posts/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, post.ID),
-			Fields: postToDoc(post).Fields,
-		},
+// runQuery runs a structured query, returning the matching documents.
+// If a transaction is provided, the query runs within it.
+// Transient failures restart the query, and any other failure is returned rather than a partial result.
+func (f *Firestore) runQuery(ctx context.Context, query *firestorepb.StructuredQuery, tx []byte) ([]*firestorepb.Document, error) {
+	return f.runQueryIn(ctx, fmt.Sprintf("projects/%s/databases/%s/documents", f.config.GCloudProjectID, f.config.FirestoreDatabaseName), query, tx)
+}
+
+// runQueryIn runs a query against collections nested under the parent document.
+func (f *Firestore) runQueryIn(ctx context.Context, parent string, query *firestorepb.StructuredQuery, tx []byte) ([]*firestorepb.Document, error) {
+	req := firestorepb.RunQueryRequest{
+		Parent:    parent,
+		QueryType: &firestorepb.RunQueryRequest_StructuredQuery{StructuredQuery: query},
+	}
+	if tx != nil {
+		req.ConsistencySelector = &firestorepb.RunQueryRequest_Transaction{Transaction: tx}
 	}
-	_, err := f.client.UpdateDocument(ctx, &req)
+	var docs []*firestorepb.Document
+	err := retry(ctx, f.backoff, func() error {
+		it, err := f.queryIterator(ctx, &req)
+		if err != nil {
+			return err
+		}
+		docs, err = collect(it)
+		return err
+	})
 	if err != nil {
-		return types.WrapErr(err, "failed to update post")
+		return nil, wrapErr(err, "failed to run query")
+	}
+
+	return docs, nil
+}
+
+// paginate limits a query to a page of results, starting after the page's cursor (if any).
+// One more result than the page's limit is requested, to detect whether a further page exists.
+func (f *Firestore) paginate(query *firestorepb.StructuredQuery, collection string, page Page) error {
+	if page.Limit > 0 {
+		query.Limit = wrapperspb.Int32(int32(page.Limit + 1))
+	}
+	if page.Cursor == "" {
+		return nil
 	}
 
+	c, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return err
+	}
+	query.StartAt = &firestorepb.Cursor{
+		Values: []*firestorepb.Value{
+			timestampValue(c.Time),
+			referenceValue(fmt.Sprintf("projects/%s/databases/%s/documents/%s/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, collection, c.ID)),
+		},
+		Before: false,
+	}
 	return nil
 }
 
-func (f *Firestore) DeletePost(id string) error {
+// pageResults trims the results of a paginated query to the page's limit.
+// If further results exist, a cursor positioned at the last returned document is also returned.
+func pageResults(docs []*firestorepb.Document, field string, page Page) ([]*firestorepb.Document, string) {
+	if page.Limit <= 0 || len(docs) <= page.Limit {
+		return docs, ""
+	}
+	docs = docs[:page.Limit]
+	last := docs[len(docs)-1]
+	return docs, encodeCursor(last.Fields[field].GetTimestampValue().AsTime(), id(last))
+}
+
+// revisionWrite snapshots the current version of a post into its 'revisions' subcollection.
+// The revision's creation time is set to the commit time by the server.
+func revisionWrite(current *firestorepb.Document) *firestorepb.Write {
+	doc := &firestorepb.Document{
+		Name:   current.Name + "/revisions/" + uuid.New().String(),
+		Fields: maps.Clone(current.Fields),
+	}
+	doc.Fields["updated"] = timestampValue(current.UpdateTime.AsTime())
+	return &firestorepb.Write{
+		Operation: &firestorepb.Write_Update{Update: doc},
+		UpdateTransforms: []*firestorepb.DocumentTransform_FieldTransform{{
+			FieldPath: "created",
+			TransformType: &firestorepb.DocumentTransform_FieldTransform_SetToServerValue{
+				SetToServerValue: firestorepb.DocumentTransform_FieldTransform_REQUEST_TIME,
+			},
+		}},
+		CurrentDocument: &firestorepb.Precondition{
+			ConditionType: &firestorepb.Precondition_Exists{Exists: false},
+		},
+	}
+}
+
+// runTransaction begins a read-write transaction, builds the writes to apply with fn, then commits them.
+// If fn returns an error the transaction is rolled back and the error is returned as-is.
+// Transactions aborted by contention with a concurrent transaction are run again, so fn may be called more than once.
+func (f *Firestore) runTransaction(ctx context.Context, fn func(tx []byte) ([]*firestorepb.Write, error)) (*firestorepb.CommitResponse, error) {
+	database := fmt.Sprintf("projects/%s/databases/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName)
+	var resp *firestorepb.CommitResponse
+	var previous []byte
+	err := retryWhen(ctx, f.backoff, aborted, func() error {
+		req := &firestorepb.BeginTransactionRequest{Database: database}
+		if previous != nil {
+			// Retrying the aborted transaction keeps its place in the queue for contended documents
+			req.Options = &firestorepb.TransactionOptions{
+				Mode: &firestorepb.TransactionOptions_ReadWrite_{
+					ReadWrite: &firestorepb.TransactionOptions_ReadWrite{RetryTransaction: previous},
+				},
+			}
+		}
+		begin, err := f.client.BeginTransaction(ctx, req)
+		if err != nil {
+			return wrapErr(err, "failed to begin transaction")
+		}
+		previous = begin.Transaction
+
+		writes, err := fn(begin.Transaction)
+		if err != nil {
+			_ = f.client.Rollback(ctx, &firestorepb.RollbackRequest{Database: database, Transaction: begin.Transaction})
+			return err
+		}
+
+		resp, err = f.client.Commit(ctx, &firestorepb.CommitRequest{Database: database, Writes: writes, Transaction: begin.Transaction})
+		if err != nil {
+			return wrapErr(err, "failed to commit transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// PatchPost writes the patched fields with an update mask, so other fields are left untouched.
+func (f *Firestore) PatchPost(patch types.Post, fields []string) (types.Post, error) {
 	ctx := context.Background()
-	req := firestorepb.DeleteDocumentRequest{
-		Name: fmt.Sprintf("projects/%s/databases/%s/documents/web-posts/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, id),
+	name := fmt.Sprintf("projects/%s/databases/%s/documents/web-posts/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, patch.ID)
+
+	precondition := &firestorepb.Precondition{ConditionType: &firestorepb.Precondition_Exists{Exists: true}}
+	if !patch.Updated.IsZero() {
+		precondition = &firestorepb.Precondition{ConditionType: &firestorepb.Precondition_UpdateTime{UpdateTime: timestamppb.New(patch.Updated)}}
 	}
-	err := f.client.DeleteDocument(ctx, &req)
+
+	var post types.Post
+	resp, err := f.runTransaction(ctx, func(tx []byte) ([]*firestorepb.Write, error) {
+		// The current post is needed to generate a slug from its title, and to return the patched post
+		current, err := f.client.GetDocument(ctx, &firestorepb.GetDocumentRequest{
+			Name:                name,
+			ConsistencySelector: &firestorepb.GetDocumentRequest_Transaction{Transaction: tx},
+		})
+		if err != nil {
+			return nil, wrapErr(err, "failed to get post")
+		}
+
+		post, err = applyPatch(docToPost(current), patch, fields)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(fields, "slug") {
+			taken, err := f.slugTaken(ctx, tx, post.Slug, post.ID)
+			if err != nil {
+				return nil, err
+			}
+			if taken {
+				return nil, fmt.Errorf("slug '%s' already in use; %w", post.Slug, ErrConflict)
+			}
+		}
+
+		// The document may only hold fields named in the update mask
+		mask := patchMask(fields)
+		doc := postToDoc(post)
+		doc.Name = name
+		for field := range doc.Fields {
+			if !slices.Contains(mask, field) {
+				delete(doc.Fields, field)
+			}
+		}
+		return []*firestorepb.Write{{
+			Operation:       &firestorepb.Write_Update{Update: doc},
+			UpdateMask:      &firestorepb.DocumentMask{FieldPaths: mask},
+			CurrentDocument: precondition,
+		}, revisionWrite(current)}, nil
+	})
 	if err != nil {
-		return types.WrapErr(err, "failed to delete like")
+		// A stale update time is reported as a failed precondition on commit
+		if !patch.Updated.IsZero() && status.Code(err) == codes.FailedPrecondition {
+			return types.Post{}, fmt.Errorf("post '%s' has changed; %w; %w", patch.ID, ErrPreconditionFailed, err)
+		}
+		return types.Post{}, types.WrapErr(err, "failed to patch post")
+	}
+
+	post.Updated = resp.WriteResults[0].GetUpdateTime().AsTime()
+	return post, nil
+}
+
+// deleteBatchSize is the number of revisions deleted per commit, as Firestore allows at most 500 writes in a commit.
+const deleteBatchSize = 400
+
+// DeletePost deletes revisions in batches, as subcollections aren't deleted with their parent document,
+// and deletes the post alongside the last batch.
+func (f *Firestore) DeletePost(id string) error {
+	ctx := context.Background()
+	name := fmt.Sprintf("projects/%s/databases/%s/documents/web-posts/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, id)
+
+	for done := false; !done; {
+		_, err := f.runTransaction(ctx, func(tx []byte) ([]*firestorepb.Write, error) {
+			query := &firestorepb.StructuredQuery{
+				From:   []*firestorepb.StructuredQuery_CollectionSelector{{CollectionId: "revisions"}},
+				Select: projection("__name__"),
+				Limit:  wrapperspb.Int32(deleteBatchSize),
+			}
+			revisions, err := f.runQueryIn(ctx, name, query, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			writes := make([]*firestorepb.Write, 0, len(revisions)+1)
+			for _, revision := range revisions {
+				writes = append(writes, &firestorepb.Write{Operation: &firestorepb.Write_Delete{Delete: revision.Name}})
+			}
+			done = len(revisions) < deleteBatchSize
+			if done {
+				writes = append(writes, &firestorepb.Write{
+					Operation: &firestorepb.Write_Delete{Delete: name},
+					CurrentDocument: &firestorepb.Precondition{
+						ConditionType: &firestorepb.Precondition_Exists{Exists: true},
+					},
+				})
+			}
+			return writes, nil
+		})
+		if err != nil {
+			return types.WrapErr(err, "failed to delete post")
+		}
 	}
 
 	return nil
 }
 
+// GetRevisions reads only the fields of each revision needed to summarize it.
+func (f *Firestore) GetRevisions(postID string, page Page) ([]types.RevisionSummary, string, error) {
+	ctx := context.Background()
+	name := fmt.Sprintf("projects/%s/databases/%s/documents/web-posts/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, postID)
+
+	// Check the post exists, as querying a missing document's subcollection isn't an error
+	err := retry(ctx, f.backoff, func() error {
+		_, err := f.client.GetDocument(ctx, &firestorepb.GetDocumentRequest{
+			Name: name,
+			Mask: &firestorepb.DocumentMask{FieldPaths: []string{"slug"}},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, "", wrapErr(err, "failed to get post")
+	}
+
+	query := &firestorepb.StructuredQuery{
+		From:   []*firestorepb.StructuredQuery_CollectionSelector{{CollectionId: "revisions"}},
+		Select: projection("created", "title", "slug", "updated"),
+		OrderBy: []*firestorepb.StructuredQuery_Order{
+			order("created", firestorepb.StructuredQuery_DESCENDING),
+			order("__name__", firestorepb.StructuredQuery_DESCENDING),
+		},
+	}
+	if err := f.paginate(query, "web-posts/"+postID+"/revisions", page); err != nil {
+		return nil, "", err
+	}
+	docs, err := f.runQueryIn(ctx, name, query, nil)
+	if err != nil {
+		return nil, "", types.WrapErr(err, "failed to get revisions")
+	}
+
+	docs, next := pageResults(docs, "created", page)
+	revisions := make([]types.RevisionSummary, len(docs))
+	for i, doc := range docs {
+		revisions[i] = docToRevisionSummary(doc)
+	}
+	return revisions, next, nil
+}
+
+func (f *Firestore) GetRevision(postID string, revisionID string) (types.Revision, error) {
+	ctx := context.Background()
+	req := firestorepb.GetDocumentRequest{
+		Name: fmt.Sprintf("projects/%s/databases/%s/documents/web-posts/%s/revisions/%s", f.config.GCloudProjectID, f.config.FirestoreDatabaseName, postID, revisionID),
+	}
+	var doc *firestorepb.Document
+	err := retry(ctx, f.backoff, func() error {
+		var err error
+		doc, err = f.client.GetDocument(ctx, &req)
+		return err
+	})
+	if err != nil {
+		return types.Revision{}, wrapErr(err, "failed to get revision")
+	}
+
+	return docToRevision(doc), nil
+}
+
 func (f *Firestore) Close() {
 	f.client.Close()
 }
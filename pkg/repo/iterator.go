@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/api/iterator"
+)
+
+// documentIterator yields documents one at a time, returning iterator.Done once exhausted.
+type documentIterator interface {
+	Next() (*firestorepb.Document, error)
+}
+
+// queryIterator yields the documents returned by a RunQuery stream.
+type queryIterator struct {
+	stream firestorepb.Firestore_RunQueryClient
+}
+
+// Next returns the next document in the stream, skipping responses that only report progress.
+func (q *queryIterator) Next() (*firestorepb.Document, error) {
+	for {
+		resp, err := q.stream.Recv()
+		if err == io.EOF {
+			return nil, iterator.Done
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp.Document != nil {
+			return resp.Document, nil
+		}
+	}
+}
+
+// newQueryIterator runs a query, returning an iterator over its results.
+func (f *Firestore) newQueryIterator(ctx context.Context, req *firestorepb.RunQueryRequest) (documentIterator, error) {
+	stream, err := f.client.RunQuery(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &queryIterator{stream: stream}, nil
+}
+
+// collect drains an iterator. Unlike iterator.Done, any other error is returned rather than
+// treated as the end of the results, so failures are never mistaken for a partial result.
+func collect(it documentIterator) ([]*firestorepb.Document, error) {
+	docs := make([]*firestorepb.Document, 0)
+	for {
+		doc, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
@@ -0,0 +1,139 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeIterator yields its documents, then its error (or iterator.Done if there is none).
+type fakeIterator struct {
+	docs []*firestorepb.Document
+	err  error
+}
+
+func (f *fakeIterator) Next() (*firestorepb.Document, error) {
+	if len(f.docs) > 0 {
+		doc := f.docs[0]
+		f.docs = f.docs[1:]
+		return doc, nil
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return nil, iterator.Done
+}
+
+// fakeService returns a service whose queries are answered by the given iterators, in order.
+// The number of queries run is recorded in calls.
+func fakeService(calls *int, iterators ...*fakeIterator) *Firestore {
+	return &Firestore{
+		backoff: backoff{attempts: 3, initial: time.Millisecond, max: time.Millisecond},
+		queryIterator: func(ctx context.Context, req *firestorepb.RunQueryRequest) (documentIterator, error) {
+			it := iterators[*calls]
+			*calls++
+			return it, nil
+		},
+	}
+}
+
+func likeDoc(id string) *firestorepb.Document {
+	return &firestorepb.Document{
+		Name: "projects/p/databases/d/documents/web-likes/" + id,
+		Fields: map[string]*firestorepb.Value{
+			"timestamp": {ValueType: &firestorepb.Value_TimestampValue{TimestampValue: timestamppb.New(time.Now())}},
+		},
+	}
+}
+
+func TestCollect(t *testing.T) {
+	// ==================== Test case 1: Iterator exhausted ====================
+	docs, err := collect(&fakeIterator{docs: []*firestorepb.Document{likeDoc("a"), likeDoc("b")}})
+	if err != nil {
+		t.Errorf("failed to collect documents; %s", err)
+	}
+	if len(docs) != 2 {
+		t.Errorf("expected 2 documents, got %d", len(docs))
+	}
+
+	// ==================== Test case 2: Iterator fails part way ====================
+	expected := errors.New("ope")
+	docs, err = collect(&fakeIterator{docs: []*firestorepb.Document{likeDoc("a")}, err: expected})
+	if !errors.Is(err, expected) {
+		t.Errorf("expected error %v, got %v", expected, err)
+	}
+	if docs != nil {
+		t.Errorf("expected no documents, got %d", len(docs))
+	}
+}
+
+func TestGetLikesIteratorErrors(t *testing.T) {
+	// ==================== Test case 1: Non-retryable failure ====================
+	calls := 0
+	service := fakeService(&calls, &fakeIterator{docs: []*firestorepb.Document{likeDoc("a")}, err: status.Error(codes.PermissionDenied, "ope")})
+	likes, err := service.GetLikes()
+	if err == nil {
+		t.Errorf("expected error, got %d likes", len(likes))
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 query, got %d", calls)
+	}
+
+	// ==================== Test case 2: Transient failure, then success ====================
+	calls = 0
+	service = fakeService(&calls,
+		&fakeIterator{docs: []*firestorepb.Document{likeDoc("a")}, err: status.Error(codes.Unavailable, "ope")},
+		&fakeIterator{docs: []*firestorepb.Document{likeDoc("a"), likeDoc("b")}},
+	)
+	likes, err = service.GetLikes()
+	if err != nil {
+		t.Errorf("failed to get likes; %s", err)
+	}
+	if len(likes) != 2 {
+		t.Errorf("expected 2 likes, got %d", len(likes))
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 queries, got %d", calls)
+	}
+
+	// ==================== Test case 3: Transient failures exhaust retries ====================
+	calls = 0
+	unavailable := status.Error(codes.Unavailable, "ope")
+	service = fakeService(&calls, &fakeIterator{err: unavailable}, &fakeIterator{err: unavailable}, &fakeIterator{err: unavailable})
+	_, err = service.GetLikes()
+	if !errors.Is(err, unavailable) {
+		t.Errorf("expected unavailable error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 queries, got %d", calls)
+	}
+}
+
+func TestGetPostsIteratorErrors(t *testing.T) {
+	calls := 0
+	expected := status.Error(codes.Internal, "ope")
+	service := fakeService(&calls, &fakeIterator{err: expected})
+	posts, err := service.GetPosts(PostFilters{})
+	if !errors.Is(err, expected) {
+		t.Errorf("expected error %v, got %v", expected, err)
+	}
+	if posts != nil {
+		t.Errorf("expected no posts, got %v", posts)
+	}
+}
+
+func TestGetPostBySlugIteratorErrors(t *testing.T) {
+	calls := 0
+	service := fakeService(&calls, &fakeIterator{err: errors.New("ope")})
+	_, err := service.GetPostBySlug("slug", PostFilters{})
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected failure rather than not found, got %v", err)
+	}
+}
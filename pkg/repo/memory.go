@@ -0,0 +1,366 @@
+package repo
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/types"
+	"github.com/google/uuid"
+)
+
+// Memory stores posts and likes in memory, for local development and tests.
+// Nothing is persisted once the process exits.
+type Memory struct {
+	mu    sync.RWMutex
+	likes map[string]types.Like
+	posts map[string]types.Post
+	// revisions holds the revisions of each post, oldest first
+	revisions map[string][]types.Revision
+
+	// updated is the most recent update time handed out, guarded by mu
+	updated time.Time
+}
+
+func NewMemoryService() *Memory {
+	return &Memory{
+		likes:     make(map[string]types.Like),
+		posts:     make(map[string]types.Post),
+		revisions: make(map[string][]types.Revision),
+	}
+}
+
+func (m *Memory) GetLike(id string) (types.Like, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	like, ok := m.likes[id]
+	if !ok {
+		return types.Like{}, fmt.Errorf("failed to get like '%s'; %w", id, ErrNotFound)
+	}
+	return like, nil
+}
+
+func (m *Memory) GetLikes() ([]types.Like, error) {
+	likes, _, err := m.GetLikesPage(Page{})
+	return likes, err
+}
+
+func (m *Memory) GetLikesPage(page Page) ([]types.Like, string, error) {
+	m.mu.RLock()
+	likes := make([]types.Like, 0, len(m.likes))
+	for _, like := range m.likes {
+		likes = append(likes, like)
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(likes, func(a, b types.Like) int {
+		return compareDesc(a.Timestamp, a.ID, b.Timestamp, b.ID)
+	})
+	return paginateSlice(likes, page, func(like types.Like) (time.Time, string) {
+		return like.Timestamp, like.ID
+	})
+}
+
+func (m *Memory) AddLike(like types.Like) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	like.ID = uuid.New().String()
+	m.likes[like.ID] = like
+	return like.ID, nil
+}
+
+func (m *Memory) PutLike(like types.Like) error {
+	if like.ID == "" {
+		return fmt.Errorf("like has no id; %w", ErrInvalid)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.likes[like.ID] = like
+	return nil
+}
+
+func (m *Memory) DeleteLike(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.likes[id]; !ok {
+		return fmt.Errorf("failed to delete like '%s'; %w", id, ErrNotFound)
+	}
+	delete(m.likes, id)
+	return nil
+}
+
+func (m *Memory) GetPost(id string) (types.Post, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	post, ok := m.posts[id]
+	if !ok {
+		return types.Post{}, fmt.Errorf("failed to get post '%s'; %w", id, ErrNotFound)
+	}
+	return copyPost(post), nil
+}
+
+func (m *Memory) GetPosts(filters PostFilters) ([]types.Post, error) {
+	posts, _, err := m.GetPostsPage(filters, Page{})
+	return posts, err
+}
+
+func (m *Memory) GetPostsPage(filters PostFilters, page Page) ([]types.Post, string, error) {
+	now := time.Now()
+	m.mu.RLock()
+	posts := make([]types.Post, 0, len(m.posts))
+	for _, post := range m.posts {
+		if filters.matches(post, now) {
+			posts = append(posts, copyPost(post))
+		}
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(posts, func(a, b types.Post) int {
+		return compareDesc(a.Published, a.ID, b.Published, b.ID)
+	})
+	return paginateSlice(posts, page, func(post types.Post) (time.Time, string) {
+		return post.Published, post.ID
+	})
+}
+
+func (m *Memory) GetPostBySlug(slug string, filters PostFilters) (types.Post, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	for _, post := range m.posts {
+		if post.Slug == slug && filters.matches(post, now) {
+			return copyPost(post), nil
+		}
+	}
+	return types.Post{}, fmt.Errorf("no post with slug '%s'; %w", slug, ErrNotFound)
+}
+
+func (m *Memory) AddPost(post types.Post) (string, error) {
+	post, err := preparePost(post)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	post.ID = uuid.New().String()
+	if m.slugTaken(post.Slug, post.ID) {
+		return "", fmt.Errorf("failed to create post; slug '%s' already in use; %w", post.Slug, ErrConflict)
+	}
+	post.Updated = m.updateTime()
+	m.posts[post.ID] = copyPost(post)
+	return post.ID, nil
+}
+
+func (m *Memory) PutPost(post types.Post) error {
+	if post.ID == "" {
+		return fmt.Errorf("post has no id; %w", ErrInvalid)
+	}
+	post, err := preparePost(post)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.slugTaken(post.Slug, post.ID) {
+		return fmt.Errorf("failed to put post; slug '%s' already in use; %w", post.Slug, ErrConflict)
+	}
+	if existing, ok := m.posts[post.ID]; ok {
+		m.replacePost(existing, post)
+		return nil
+	}
+	post.Updated = m.updateTime()
+	m.posts[post.ID] = copyPost(post)
+	return nil
+}
+
+func (m *Memory) UpdatePost(post types.Post) (time.Time, error) {
+	post, err := preparePost(post)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.posts[post.ID]
+	if !ok {
+		return time.Time{}, fmt.Errorf("failed to update post '%s'; %w", post.ID, ErrNotFound)
+	}
+	if !post.Updated.IsZero() && !post.Updated.Equal(existing.Updated) {
+		return time.Time{}, fmt.Errorf("post '%s' has changed; %w", post.ID, ErrPreconditionFailed)
+	}
+	if m.slugTaken(post.Slug, post.ID) {
+		return time.Time{}, fmt.Errorf("failed to update post; slug '%s' already in use; %w", post.Slug, ErrConflict)
+	}
+	m.replacePost(existing, post)
+	return m.posts[post.ID].Updated, nil
+}
+
+func (m *Memory) PatchPost(patch types.Post, fields []string) (types.Post, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.posts[patch.ID]
+	if !ok {
+		return types.Post{}, fmt.Errorf("failed to patch post '%s'; %w", patch.ID, ErrNotFound)
+	}
+	if !patch.Updated.IsZero() && !patch.Updated.Equal(existing.Updated) {
+		return types.Post{}, fmt.Errorf("post '%s' has changed; %w", patch.ID, ErrPreconditionFailed)
+	}
+	post, err := applyPatch(copyPost(existing), patch, fields)
+	if err != nil {
+		return types.Post{}, err
+	}
+	if m.slugTaken(post.Slug, post.ID) {
+		return types.Post{}, fmt.Errorf("failed to patch post; slug '%s' already in use; %w", post.Slug, ErrConflict)
+	}
+	m.replacePost(existing, post)
+	return copyPost(m.posts[post.ID]), nil
+}
+
+func (m *Memory) DeletePost(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.posts[id]; !ok {
+		return fmt.Errorf("failed to delete post '%s'; %w", id, ErrNotFound)
+	}
+	delete(m.posts, id)
+	delete(m.revisions, id)
+	return nil
+}
+
+func (m *Memory) GetRevisions(postID string, page Page) ([]types.RevisionSummary, string, error) {
+	m.mu.RLock()
+	if _, ok := m.posts[postID]; !ok {
+		m.mu.RUnlock()
+		return nil, "", fmt.Errorf("failed to get revisions of post '%s'; %w", postID, ErrNotFound)
+	}
+	revisions := make([]types.RevisionSummary, 0, len(m.revisions[postID]))
+	for _, revision := range m.revisions[postID] {
+		revisions = append(revisions, types.RevisionSummary{
+			ID:      revision.ID,
+			Created: revision.Created,
+			Title:   revision.Post.Title,
+			Slug:    revision.Post.Slug,
+			Updated: revision.Post.Updated,
+		})
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(revisions, func(a, b types.RevisionSummary) int {
+		return compareDesc(a.Created, a.ID, b.Created, b.ID)
+	})
+	return paginateSlice(revisions, page, func(revision types.RevisionSummary) (time.Time, string) {
+		return revision.Created, revision.ID
+	})
+}
+
+func (m *Memory) GetRevision(postID string, revisionID string) (types.Revision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, revision := range m.revisions[postID] {
+		if revision.ID == revisionID {
+			revision.Post = copyPost(revision.Post)
+			return revision, nil
+		}
+	}
+	return types.Revision{}, fmt.Errorf("failed to get revision '%s' of post '%s'; %w", revisionID, postID, ErrNotFound)
+}
+
+func (m *Memory) Close() {}
+
+// replacePost stores a new version of a post, keeping the existing version as a revision.
+// The caller must hold the lock.
+func (m *Memory) replacePost(existing types.Post, post types.Post) {
+	post.Updated = m.updateTime()
+	m.revisions[post.ID] = append(m.revisions[post.ID], types.Revision{
+		ID:      uuid.New().String(),
+		Created: post.Updated,
+		Post:    existing,
+	})
+	m.posts[post.ID] = copyPost(post)
+}
+
+// slugTaken reports whether a post other than the one with the given ID uses the slug.
+// The caller must hold the lock.
+func (m *Memory) slugTaken(slug string, postID string) bool {
+	for _, post := range m.posts {
+		if post.Slug == slug && post.ID != postID {
+			return true
+		}
+	}
+	return false
+}
+
+// updateTime returns the time to record for a write. Times are strictly increasing, so that
+// two writes in quick succession can't share an update time. The caller must hold the lock.
+func (m *Memory) updateTime() time.Time {
+	now := time.Now().UTC()
+	if !now.After(m.updated) {
+		now = m.updated.Add(time.Nanosecond)
+	}
+	m.updated = now
+	return now
+}
+
+// copyPost copies a post, so callers can't modify stored tags.
+func copyPost(post types.Post) types.Post {
+	post.Tags = append(make([]string, 0, len(post.Tags)), post.Tags...)
+	return post
+}
+
+// compareDesc orders results by time, then ID, both descending. This matches the ordering
+// applied by Firestore, which breaks ties by document name.
+func compareDesc(at time.Time, aID string, bt time.Time, bID string) int {
+	if c := bt.Compare(at); c != 0 {
+		return c
+	}
+	if aID > bID {
+		return -1
+	}
+	if aID < bID {
+		return 1
+	}
+	return 0
+}
+
+// paginateSlice selects a page from sorted results. Results at or before the page's cursor
+// are skipped, and a cursor is returned if further results exist.
+func paginateSlice[T any](results []T, page Page, position func(T) (time.Time, string)) ([]T, string, error) {
+	if page.Cursor != "" {
+		c, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start := len(results)
+		for i, result := range results {
+			t, id := position(result)
+			if compareDesc(t, id, c.Time, c.ID) > 0 {
+				start = i
+				break
+			}
+		}
+		results = results[start:]
+	}
+
+	if page.Limit <= 0 || len(results) <= page.Limit {
+		return results, "", nil
+	}
+	results = results[:page.Limit]
+	t, id := position(results[len(results)-1])
+	return results, encodeCursor(t, id), nil
+}
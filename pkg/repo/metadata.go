@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/georgemblack/web-api/pkg/render"
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// wordsPerMinute is the reading speed used to estimate reading time.
+const wordsPerMinute = 200
+
+// excerptLength is the maximum length of an excerpt in characters, not counting its ellipsis.
+const excerptLength = 280
+
+// preparePost fills in the fields of a post derived on write: its slug, generated from its title
+// if none is set, and its metadata.
+func preparePost(post types.Post) (types.Post, error) {
+	slug, err := postSlug(post)
+	if err != nil {
+		return types.Post{}, err
+	}
+	post.Slug = slug
+	return withMetadata(post), nil
+}
+
+// withMetadata derives a post's word count, reading time, and plain text excerpt from its content.
+func withMetadata(post types.Post) types.Post {
+	words := strings.Fields(render.Text(post.Content))
+	post.WordCount = len(words)
+	post.ReadingTimeMinutes = (len(words) + wordsPerMinute - 1) / wordsPerMinute
+	post.Excerpt = excerpt(words)
+	return post
+}
+
+// backfillMetadata derives the metadata of a post stored before metadata was derived on write, which
+// reads back with a zero word count. Posts without words are derived again on each read, which is cheap
+// as they have little content.
+func backfillMetadata(post types.Post) types.Post {
+	if post.WordCount > 0 {
+		return post
+	}
+	return withMetadata(post)
+}
+
+// excerpt joins as many words as fit in the excerpt length, ending with an ellipsis if any are left out.
+func excerpt(words []string) string {
+	var b strings.Builder
+	length := 0
+	for i, word := range words {
+		n := utf8.RuneCountInString(word)
+		if i > 0 {
+			n++
+		}
+		if length+n > excerptLength {
+			if i == 0 {
+				// A single word too long for the excerpt is cut short
+				b.WriteString(string([]rune(word)[:excerptLength]))
+			}
+			b.WriteString("…")
+			break
+		}
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(word)
+		length += n
+	}
+	return b.String()
+}
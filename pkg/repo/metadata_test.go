@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+func TestWithMetadata(t *testing.T) {
+	// ==================== Test case 1: Empty content ====================
+	post := withMetadata(types.Post{})
+	if post.WordCount != 0 || post.ReadingTimeMinutes != 0 || post.Excerpt != "" {
+		t.Errorf("expected no metadata for empty content, got %+v", post)
+	}
+
+	// ==================== Test case 2: Markup and code are not counted ====================
+	post = withMetadata(types.Post{Content: "# Hello *world*\n\n```go\nfmt.Println(\"ignored\")\n```\n\nA [short](https://example.com) post."})
+	if post.WordCount != 5 {
+		t.Errorf("expected 5 words, got %d", post.WordCount)
+	}
+	if post.ReadingTimeMinutes != 1 {
+		t.Errorf("expected reading time of 1 minute, got %d", post.ReadingTimeMinutes)
+	}
+	if post.Excerpt != "Hello world A short post." {
+		t.Errorf("expected excerpt 'Hello world A short post.', got '%s'", post.Excerpt)
+	}
+
+	// ==================== Test case 3: Reading time rounds up ====================
+	post = withMetadata(types.Post{Content: strings.Repeat("word ", wordsPerMinute+1)})
+	if post.WordCount != wordsPerMinute+1 {
+		t.Errorf("expected %d words, got %d", wordsPerMinute+1, post.WordCount)
+	}
+	if post.ReadingTimeMinutes != 2 {
+		t.Errorf("expected reading time of 2 minutes, got %d", post.ReadingTimeMinutes)
+	}
+
+	// ==================== Test case 4: Long excerpts end at a word ====================
+	if !strings.HasSuffix(post.Excerpt, "word…") {
+		t.Errorf("expected excerpt to end with a whole word and ellipsis, got '%s'", post.Excerpt)
+	}
+	if n := utf8.RuneCountInString(strings.TrimSuffix(post.Excerpt, "…")); n > excerptLength {
+		t.Errorf("expected excerpt of at most %d characters, got %d", excerptLength, n)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	long := strings.Repeat("é", excerptLength+10)
+	cases := []struct {
+		words    []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"one", "two"}, "one two"},
+		{[]string{strings.Repeat("a", excerptLength)}, strings.Repeat("a", excerptLength)},
+		{[]string{strings.Repeat("a", excerptLength), "b"}, strings.Repeat("a", excerptLength) + "…"},
+		{[]string{long}, strings.Repeat("é", excerptLength) + "…"},
+	}
+
+	for _, c := range cases {
+		if actual := excerpt(c.words); actual != c.expected {
+			t.Errorf("expected excerpt '%s' for words %v, got '%s'", c.expected, c.words, actual)
+		}
+	}
+}
+
+func TestBackfillMetadata(t *testing.T) {
+	content := "A post written before metadata was derived."
+
+	// ==================== Test case 1: Firestore documents without metadata fields ====================
+	doc := postToDoc(types.Post{Content: content})
+	delete(doc.Fields, "wordCount")
+	delete(doc.Fields, "readingTimeMinutes")
+	delete(doc.Fields, "excerpt")
+	post := docToPost(doc)
+	if post.WordCount != 7 || post.ReadingTimeMinutes != 1 || post.Excerpt != content {
+		t.Errorf("expected metadata derived from content, got %+v", post)
+	}
+
+	// ==================== Test case 2: Existing metadata is kept ====================
+	post = backfillMetadata(types.Post{Content: content, WordCount: 3, ReadingTimeMinutes: 2, Excerpt: "stored"})
+	if post.WordCount != 3 || post.ReadingTimeMinutes != 2 || post.Excerpt != "stored" {
+		t.Errorf("expected stored metadata, got %+v", post)
+	}
+}
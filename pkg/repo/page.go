@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Page selects a window of results.
+// A zero limit returns all results, and an empty cursor starts from the first result.
+type Page struct {
+	Limit  int
+	Cursor string
+}
+
+// cursor marks the position of the last result of a page, as the value of the field results
+// are ordered by, along with the document ID to break ties. Cursors are opaque to clients.
+type cursor struct {
+	Time time.Time `json:"t"`
+	ID   string    `json:"id"`
+}
+
+func encodeCursor(t time.Time, id string) string {
+	bytes, _ := json.Marshal(cursor{Time: t, ID: id})
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	bytes, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, fmt.Errorf("failed to decode cursor; %w; %w", ErrInvalid, err)
+	}
+	if err := json.Unmarshal(bytes, &c); err != nil || c.ID == "" {
+		return cursor{}, fmt.Errorf("failed to parse cursor; %w", ErrInvalid)
+	}
+	return c, nil
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCursor(t *testing.T) {
+	// ==================== Test case 1: Round trip ====================
+	expected := time.Date(2024, 3, 1, 12, 30, 0, 123456000, time.UTC)
+	c, err := decodeCursor(encodeCursor(expected, "abc"))
+	if err != nil {
+		t.Errorf("failed to decode cursor; %s", err)
+	}
+	if !c.Time.Equal(expected) {
+		t.Errorf("expected time %s, got %s", expected, c.Time)
+	}
+	if c.ID != "abc" {
+		t.Errorf("expected id abc, got %s", c.ID)
+	}
+
+	// ==================== Test case 2: Invalid cursors ====================
+	for _, s := range []string{"!!!", "bogus", encodeCursor(expected, "")} {
+		_, err = decodeCursor(s)
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected invalid cursor error for %s, got %v", s, err)
+		}
+	}
+}
+
+func TestPageResults(t *testing.T) {
+	now := time.Now()
+	docs := make([]*firestorepb.Document, 3)
+	for i := range docs {
+		docs[i] = &firestorepb.Document{
+			Name: "projects/p/databases/d/documents/web-likes/" + string(rune('a'+i)),
+			Fields: map[string]*firestorepb.Value{
+				"timestamp": {ValueType: &firestorepb.Value_TimestampValue{TimestampValue: timestamppb.New(now.Add(-time.Duration(i) * time.Hour))}},
+			},
+		}
+	}
+
+	// ==================== Test case 1: No limit ====================
+	results, next := pageResults(docs, "timestamp", Page{})
+	if len(results) != 3 || next != "" {
+		t.Errorf("expected 3 results and no cursor, got %d results and cursor '%s'", len(results), next)
+	}
+
+	// ==================== Test case 2: More results than limit ====================
+	results, next = pageResults(docs, "timestamp", Page{Limit: 2})
+	if len(results) != 2 {
+		t.Errorf("expected 2 results, got %d", len(results))
+	}
+	c, err := decodeCursor(next)
+	if err != nil {
+		t.Errorf("failed to decode cursor; %s", err)
+	}
+	if c.ID != "b" || !c.Time.Equal(now.Add(-time.Hour)) {
+		t.Errorf("expected cursor at second result, got %v", c)
+	}
+
+	// ==================== Test case 3: Results fit within limit ====================
+	results, next = pageResults(docs, "timestamp", Page{Limit: 3})
+	if len(results) != 3 || next != "" {
+		t.Errorf("expected 3 results and no cursor, got %d results and cursor '%s'", len(results), next)
+	}
+}
@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// patchFields maps the fields of a post that can be patched, by their JSON and Firestore name,
+// to a function copying the field from a patch onto a post.
+var patchFields = map[string]func(post *types.Post, patch types.Post){
+	"draft":              func(post *types.Post, patch types.Post) { post.Draft = patch.Draft },
+	"listed":             func(post *types.Post, patch types.Post) { post.Listed = patch.Listed },
+	"title":              func(post *types.Post, patch types.Post) { post.Title = patch.Title },
+	"slug":               func(post *types.Post, patch types.Post) { post.Slug = patch.Slug },
+	"content":            func(post *types.Post, patch types.Post) { post.Content = patch.Content },
+	"contentHtml":        func(post *types.Post, patch types.Post) { post.ContentHTML = patch.ContentHTML },
+	"contentHtmlPreview": func(post *types.Post, patch types.Post) { post.ContentHTMLPreview = patch.ContentHTMLPreview },
+	"tags":               func(post *types.Post, patch types.Post) { post.Tags = append([]string{}, patch.Tags...) },
+	"published":          func(post *types.Post, patch types.Post) { post.Published = patch.Published },
+}
+
+// applyPatch copies the named fields from a patch onto a post, then derives its metadata. If the slug
+// is patched to be empty, a new one is generated from the post's title. Returns ErrInvalid if a field
+// can't be patched.
+func applyPatch(post types.Post, patch types.Post, fields []string) (types.Post, error) {
+	if len(fields) == 0 {
+		return types.Post{}, fmt.Errorf("no fields to patch; %w", ErrInvalid)
+	}
+	for _, field := range fields {
+		apply, ok := patchFields[field]
+		if !ok {
+			return types.Post{}, fmt.Errorf("field '%s' can't be patched; %w", field, ErrInvalid)
+		}
+		apply(&post, patch)
+	}
+
+	if slices.Contains(fields, "slug") {
+		slug, err := postSlug(post)
+		if err != nil {
+			return types.Post{}, err
+		}
+		post.Slug = slug
+	}
+	return withMetadata(post), nil
+}
+
+// patchMask returns the fields written by a patch of the named fields, which includes any
+// metadata derived from them.
+func patchMask(fields []string) []string {
+	if !slices.Contains(fields, "content") {
+		return fields
+	}
+	return append(slices.Clone(fields), "wordCount", "readingTimeMinutes", "excerpt")
+}
@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"time"
+
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// postFilter translates post filters into a structured query filter, combined with any extra filters.
+// Returns nil if there is nothing to filter on.
+//
+// The 'published' filter selects posts that are not drafts and whose published date is not in the future.
+// Its negation selects drafts, or posts whose published date is not in the past.
+func postFilter(filters PostFilters, now time.Time, extra ...*firestorepb.StructuredQuery_Filter) *firestorepb.StructuredQuery_Filter {
+	conditions := extra
+	if filters.Listed != nil {
+		conditions = append(conditions, fieldFilter("listed", firestorepb.StructuredQuery_FieldFilter_EQUAL, boolValue(*filters.Listed)))
+	}
+	if filters.Published != nil && *filters.Published {
+		conditions = append(conditions,
+			fieldFilter("draft", firestorepb.StructuredQuery_FieldFilter_EQUAL, boolValue(false)),
+			fieldFilter("published", firestorepb.StructuredQuery_FieldFilter_LESS_THAN_OR_EQUAL, timestampValue(now)),
+		)
+	}
+	if filters.Published != nil && !*filters.Published {
+		conditions = append(conditions, compositeFilter(firestorepb.StructuredQuery_CompositeFilter_OR,
+			fieldFilter("draft", firestorepb.StructuredQuery_FieldFilter_EQUAL, boolValue(true)),
+			fieldFilter("published", firestorepb.StructuredQuery_FieldFilter_GREATER_THAN_OR_EQUAL, timestampValue(now)),
+		))
+	}
+
+	switch len(conditions) {
+	case 0:
+		return nil
+	case 1:
+		return conditions[0]
+	}
+	return compositeFilter(firestorepb.StructuredQuery_CompositeFilter_AND, conditions...)
+}
+
+// compositeFilter builds a structured query filter combining other filters with a logical operator.
+func compositeFilter(op firestorepb.StructuredQuery_CompositeFilter_Operator, filters ...*firestorepb.StructuredQuery_Filter) *firestorepb.StructuredQuery_Filter {
+	return &firestorepb.StructuredQuery_Filter{
+		FilterType: &firestorepb.StructuredQuery_Filter_CompositeFilter{
+			CompositeFilter: &firestorepb.StructuredQuery_CompositeFilter{
+				Op:      op,
+				Filters: filters,
+			},
+		},
+	}
+}
+
+// fieldFilter builds a structured query filter comparing a document field to a value.
+func fieldFilter(field string, op firestorepb.StructuredQuery_FieldFilter_Operator, value *firestorepb.Value) *firestorepb.StructuredQuery_Filter {
+	return &firestorepb.StructuredQuery_Filter{
+		FilterType: &firestorepb.StructuredQuery_Filter_FieldFilter{
+			FieldFilter: &firestorepb.StructuredQuery_FieldFilter{
+				Field: &firestorepb.StructuredQuery_FieldReference{FieldPath: field},
+				Op:    op,
+				Value: value,
+			},
+		},
+	}
+}
+
+// order builds a structured query ordering on a document field.
+func order(field string, direction firestorepb.StructuredQuery_Direction) *firestorepb.StructuredQuery_Order {
+	return &firestorepb.StructuredQuery_Order{
+		Field:     &firestorepb.StructuredQuery_FieldReference{FieldPath: field},
+		Direction: direction,
+	}
+}
+
+// projection selects only the named fields of matching documents.
+func projection(fields ...string) *firestorepb.StructuredQuery_Projection {
+	refs := make([]*firestorepb.StructuredQuery_FieldReference, len(fields))
+	for i, field := range fields {
+		refs[i] = &firestorepb.StructuredQuery_FieldReference{FieldPath: field}
+	}
+	return &firestorepb.StructuredQuery_Projection{Fields: refs}
+}
+
+func stringValue(s string) *firestorepb.Value {
+	return &firestorepb.Value{ValueType: &firestorepb.Value_StringValue{StringValue: s}}
+}
+
+func boolValue(b bool) *firestorepb.Value {
+	return &firestorepb.Value{ValueType: &firestorepb.Value_BooleanValue{BooleanValue: b}}
+}
+
+func timestampValue(t time.Time) *firestorepb.Value {
+	return &firestorepb.Value{ValueType: &firestorepb.Value_TimestampValue{TimestampValue: timestamppb.New(t)}}
+}
+
+func referenceValue(name string) *firestorepb.Value {
+	return &firestorepb.Value{ValueType: &firestorepb.Value_ReferenceValue{ReferenceValue: name}}
+}
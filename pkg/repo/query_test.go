@@ -0,0 +1,244 @@
+package repo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// describe formats post filters for test output.
+func describe(filters PostFilters) string {
+	format := func(b *bool) string {
+		if b == nil {
+			return "nil"
+		}
+		return fmt.Sprintf("%t", *b)
+	}
+	return fmt.Sprintf("{listed: %s, published: %s}", format(filters.Listed), format(filters.Published))
+}
+
+// evaluate applies a structured query filter to a document, supporting the subset of
+// operators and value types used by post queries.
+func evaluate(filter *firestorepb.StructuredQuery_Filter, doc *firestorepb.Document) bool {
+	if filter == nil {
+		return true
+	}
+
+	if composite := filter.GetCompositeFilter(); composite != nil {
+		for _, f := range composite.Filters {
+			result := evaluate(f, doc)
+			if composite.Op == firestorepb.StructuredQuery_CompositeFilter_AND && !result {
+				return false
+			}
+			if composite.Op == firestorepb.StructuredQuery_CompositeFilter_OR && result {
+				return true
+			}
+		}
+		return composite.Op == firestorepb.StructuredQuery_CompositeFilter_AND
+	}
+
+	field := filter.GetFieldFilter()
+	actual := doc.Fields[field.Field.FieldPath]
+	var cmp int
+	switch expected := field.Value.ValueType.(type) {
+	case *firestorepb.Value_BooleanValue:
+		if actual.GetBooleanValue() != expected.BooleanValue {
+			cmp = 1
+		}
+	case *firestorepb.Value_StringValue:
+		if actual.GetStringValue() != expected.StringValue {
+			cmp = 1
+		}
+	case *firestorepb.Value_TimestampValue:
+		cmp = actual.GetTimestampValue().AsTime().Compare(expected.TimestampValue.AsTime())
+	}
+
+	switch field.Op {
+	case firestorepb.StructuredQuery_FieldFilter_EQUAL:
+		return cmp == 0
+	case firestorepb.StructuredQuery_FieldFilter_LESS_THAN_OR_EQUAL:
+		return cmp <= 0
+	case firestorepb.StructuredQuery_FieldFilter_GREATER_THAN_OR_EQUAL:
+		return cmp >= 0
+	}
+	panic(fmt.Sprintf("unsupported operator %s", field.Op))
+}
+
+func TestPostFilter(t *testing.T) {
+	now := time.Now()
+	t1 := true
+	f1 := false
+
+	published := types.Post{Listed: true, Published: now.Add(-time.Hour)}
+	draft := types.Post{Listed: true, Draft: true, Published: now.Add(-time.Hour)}
+	future := types.Post{Listed: true, Published: now.Add(time.Hour)}
+	unlisted := types.Post{Listed: false, Published: now.Add(-time.Hour)}
+
+	cases := []struct {
+		name     string
+		filters  PostFilters
+		post     types.Post
+		expected bool
+	}{
+		{"no filters", PostFilters{}, draft, true},
+		{"published, published post", PostFilters{Published: &t1}, published, true},
+		{"published, draft post", PostFilters{Published: &t1}, draft, false},
+		{"published, future post", PostFilters{Published: &t1}, future, false},
+		{"unpublished, published post", PostFilters{Published: &f1}, published, false},
+		{"unpublished, draft post", PostFilters{Published: &f1}, draft, true},
+		{"unpublished, future post", PostFilters{Published: &f1}, future, true},
+		{"listed, listed post", PostFilters{Listed: &t1}, published, true},
+		{"listed, unlisted post", PostFilters{Listed: &t1}, unlisted, false},
+		{"unlisted, unlisted post", PostFilters{Listed: &f1}, unlisted, true},
+		{"unlisted, listed post", PostFilters{Listed: &f1}, published, false},
+		{"published and listed, unlisted post", PostFilters{Published: &t1, Listed: &t1}, unlisted, false},
+	}
+
+	for _, tc := range cases {
+		if actual := evaluate(postFilter(tc.filters, now), postToDoc(tc.post)); actual != tc.expected {
+			t.Errorf("%s: expected %t, got %t", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestPostFilterMatchesInMemoryFilter(t *testing.T) {
+	now := time.Now()
+	t1 := true
+	f1 := false
+
+	for _, listed := range []*bool{nil, &t1, &f1} {
+		for _, published := range []*bool{nil, &t1, &f1} {
+			filters := PostFilters{Listed: listed, Published: published}
+			for _, draft := range []bool{true, false} {
+				for _, isListed := range []bool{true, false} {
+					for _, date := range []time.Time{now.Add(-time.Hour), now, now.Add(time.Hour)} {
+						post := types.Post{Draft: draft, Listed: isListed, Published: date}
+						expected := filters.matches(post, now)
+						actual := evaluate(postFilter(filters, now), postToDoc(post))
+						if actual != expected {
+							t.Errorf("filters %s, post {draft: %t, listed: %t, published: %s}: expected %t, got %t", describe(filters), draft, isListed, date, expected, actual)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// conjunctions expands a structured query filter into the conjunctions of field filters it is equivalent
+// to, as Firestore evaluates each conjunction of an OR query with its own index.
+func conjunctions(filter *firestorepb.StructuredQuery_Filter) [][]*firestorepb.StructuredQuery_FieldFilter {
+	if filter == nil {
+		return [][]*firestorepb.StructuredQuery_FieldFilter{nil}
+	}
+	if field := filter.GetFieldFilter(); field != nil {
+		return [][]*firestorepb.StructuredQuery_FieldFilter{{field}}
+	}
+
+	composite := filter.GetCompositeFilter()
+	if composite.Op == firestorepb.StructuredQuery_CompositeFilter_OR {
+		var result [][]*firestorepb.StructuredQuery_FieldFilter
+		for _, f := range composite.Filters {
+			result = append(result, conjunctions(f)...)
+		}
+		return result
+	}
+	result := [][]*firestorepb.StructuredQuery_FieldFilter{nil}
+	for _, f := range composite.Filters {
+		var expanded [][]*firestorepb.StructuredQuery_FieldFilter
+		for _, prefix := range result {
+			for _, c := range conjunctions(f) {
+				expanded = append(expanded, append(slices.Clone(prefix), c...))
+			}
+		}
+		result = expanded
+	}
+	return result
+}
+
+// indexKey identifies a composite index by its equality fields, in any order, followed by the field it
+// is ranged or ordered on.
+func indexKey(equalities []string, field string, order string) string {
+	slices.Sort(equalities)
+	return strings.Join(equalities, ",") + "|" + field + " " + order
+}
+
+// TestFirestoreIndexes verifies firestore.indexes.json holds a composite index for every post query,
+// as Firestore rejects queries without one. The emulator doesn't require indexes, so the contract
+// tests can't catch a missing one.
+func TestFirestoreIndexes(t *testing.T) {
+	bytes, err := os.ReadFile("../../firestore.indexes.json")
+	if err != nil {
+		t.Fatalf("failed to read indexes; %s", err)
+	}
+	var file struct {
+		Indexes []struct {
+			CollectionGroup string `json:"collectionGroup"`
+			Fields          []struct {
+				FieldPath string `json:"fieldPath"`
+				Order     string `json:"order"`
+			} `json:"fields"`
+		} `json:"indexes"`
+	}
+	if err := json.Unmarshal(bytes, &file); err != nil {
+		t.Fatalf("failed to parse indexes; %s", err)
+	}
+	indexes := make(map[string]bool)
+	for _, index := range file.Indexes {
+		if index.CollectionGroup != "web-posts" || len(index.Fields) == 0 {
+			continue
+		}
+		equalities := make([]string, 0, len(index.Fields)-1)
+		for _, field := range index.Fields[:len(index.Fields)-1] {
+			equalities = append(equalities, field.FieldPath)
+		}
+		last := index.Fields[len(index.Fields)-1]
+		indexes[indexKey(equalities, last.FieldPath, last.Order)] = true
+	}
+
+	now := time.Now()
+	t1 := true
+	f1 := false
+	slug := fieldFilter("slug", firestorepb.StructuredQuery_FieldFilter_EQUAL, stringValue("slug"))
+	for _, listed := range []*bool{nil, &t1, &f1} {
+		for _, published := range []*bool{nil, &t1, &f1} {
+			filters := PostFilters{Listed: listed, Published: published}
+
+			// Pages of posts are ordered by publish date, descending
+			for _, c := range conjunctions(postFilter(filters, now)) {
+				var equalities []string
+				for _, field := range c {
+					if field.Op == firestorepb.StructuredQuery_FieldFilter_EQUAL {
+						equalities = append(equalities, field.Field.FieldPath)
+					}
+				}
+				if len(equalities) > 0 && !indexes[indexKey(equalities, "published", "DESCENDING")] {
+					t.Errorf("filters %s: no index for %v ordered by published", describe(filters), equalities)
+				}
+			}
+
+			// Lookups by slug aren't ordered, so only need an index when ranged on publish date
+			for _, c := range conjunctions(postFilter(filters, now, slug)) {
+				var equalities []string
+				ranged := false
+				for _, field := range c {
+					if field.Op == firestorepb.StructuredQuery_FieldFilter_EQUAL {
+						equalities = append(equalities, field.Field.FieldPath)
+					} else {
+						ranged = true
+					}
+				}
+				if ranged && !indexes[indexKey(equalities, "published", "ASCENDING")] {
+					t.Errorf("filters %s: no index for %v ranged on published", describe(filters), equalities)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,1216 @@
+// Package repotest provides a contract test suite that every repo.Store implementation must pass,
+// so that storage backends remain interchangeable.
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/repo"
+	"github.com/georgemblack/web-api/pkg/types"
+	"github.com/google/uuid"
+)
+
+// Run runs the contract test suite against a store created by newStore.
+// The store may already hold data, so tests only inspect the posts and likes they create.
+func Run(t *testing.T, newStore func(t *testing.T) repo.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, service repo.Store)
+	}{
+		{"AddGetLike", testAddGetLike},
+		{"GetLikesPage", testGetLikesPage},
+		{"MissingLike", testMissingLike},
+		{"PutLike", testPutLike},
+		{"AddGetPost", testAddGetPost},
+		{"GetPosts", testGetPosts},
+		{"GetPostsPage", testGetPostsPage},
+		{"GetPostsWithFilters", testGetPostsWithFilters},
+		{"PostFilters", testPostFilters},
+		{"GetPostBySlug", testGetPostBySlug},
+		{"Slugs", testSlugs},
+		{"UpdatePost", testUpdatePost},
+		{"ConditionalUpdate", testConditionalUpdate},
+		{"PatchPost", testPatchPost},
+		{"Revisions", testRevisions},
+		{"DeleteManyRevisions", testDeleteManyRevisions},
+		{"Metadata", testMetadata},
+		{"PutPost", testPutPost},
+		{"MissingPost", testMissingPost},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := newStore(t)
+			test.fn(t, service)
+		})
+	}
+}
+
+// testSlug generates a unique slug, as slugs must not collide with those of existing posts.
+func testSlug() string {
+	return "test-" + uuid.New().String()
+}
+
+func postIn(id string, posts []types.Post) bool {
+	for _, post := range posts {
+		if post.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// kind labels a post by its draft, listed, and past/future published date properties.
+func kind(draft bool, listed bool, future bool) string {
+	k := "past"
+	if future {
+		k = "future"
+	}
+	if draft {
+		k += "-draft"
+	}
+	if !listed {
+		k += "-unlisted"
+	}
+	return k
+}
+
+// describe formats post filters for test output.
+func describe(filters repo.PostFilters) string {
+	format := func(b *bool) string {
+		if b == nil {
+			return "nil"
+		}
+		return fmt.Sprintf("%t", *b)
+	}
+	return fmt.Sprintf("{listed: %s, published: %s}", format(filters.Listed), format(filters.Published))
+}
+
+func testAddGetLike(t *testing.T, service repo.Store) {
+	// Add like
+	expected := types.Like{
+		ID:        "",
+		Timestamp: time.Now(),
+		Title:     "test title",
+		URL:       "http://test.com",
+	}
+	id, err := service.AddLike(expected)
+	if err != nil {
+		t.Errorf("failed to post like; %s", err)
+	}
+
+	// Read like
+	actual, err := service.GetLike(id)
+	if err != nil {
+		t.Errorf("failed to get like; %s", err)
+	}
+
+	// Delete like
+	err = service.DeleteLike(id)
+	if err != nil {
+		t.Errorf("failed to delete like; %s", err)
+	}
+
+	// Compare
+	if expected.Timestamp.Unix() != actual.Timestamp.Unix() {
+		t.Errorf("expected timestamp %s, got %s", expected.Timestamp, actual.Timestamp)
+	}
+	if actual.Title != expected.Title {
+		t.Errorf("expected title %s, got %s", expected.Title, actual.Title)
+	}
+	if actual.URL != expected.URL {
+		t.Errorf("expected url %s, got %s", expected.URL, actual.URL)
+	}
+}
+
+func testGetLikesPage(t *testing.T, service repo.Store) {
+	// Add three likes, timestamped in the future so they are ordered ahead of existing likes
+	base := time.Now().AddDate(100, 0, 0)
+	ids := make([]string, 3)
+	for i := range ids {
+		like := types.Like{
+			ID:        "",
+			Timestamp: base.Add(-time.Duration(i) * time.Hour),
+			Title:     "test title",
+			URL:       "http://test.com",
+		}
+		id, err := service.AddLike(like)
+		if err != nil {
+			t.Errorf("failed to add like; %s", err)
+		}
+		ids[i] = id
+	}
+
+	// Read first page
+	first, next, err := service.GetLikesPage(repo.Page{Limit: 2})
+	if err != nil {
+		t.Errorf("failed to get likes; %s", err)
+	}
+	if len(first) != 2 || first[0].ID != ids[0] || first[1].ID != ids[1] {
+		t.Errorf("expected first page to contain %s and %s, got %v", ids[0], ids[1], first)
+	}
+	if next == "" {
+		t.Error("expected cursor for next page")
+	}
+
+	// Read second page
+	second, _, err := service.GetLikesPage(repo.Page{Limit: 2, Cursor: next})
+	if err != nil {
+		t.Errorf("failed to get likes; %s", err)
+	}
+	if len(second) == 0 || second[0].ID != ids[2] {
+		t.Errorf("expected second page to start with %s, got %v", ids[2], second)
+	}
+
+	// Delete likes
+	for _, id := range ids {
+		err = service.DeleteLike(id)
+		if err != nil {
+			t.Errorf("failed to delete like; %s", err)
+		}
+	}
+}
+
+func testAddGetPost(t *testing.T, service repo.Store) {
+	// Add post
+	expected := types.Post{
+		ID:                 "",
+		Draft:              false,
+		Listed:             true,
+		Title:              "test title",
+		Slug:               testSlug(),
+		Content:            "#test content",
+		ContentHTML:        "<h1>test content</h1>",
+		ContentHTMLPreview: "<h1>test content</h1>",
+		Tags:               []string{"test", "tag"},
+		Published:          time.Now(),
+	}
+	id, err := service.AddPost(expected)
+	if err != nil {
+		t.Errorf("failed to post post; %s", err)
+	}
+
+	// Read post
+	actual, err := service.GetPost(id)
+	if err != nil {
+		t.Errorf("failed to get post; %s", err)
+	}
+
+	// Delete post
+	err = service.DeletePost(id)
+	if err != nil {
+		t.Errorf("failed to delete post; %s", err)
+	}
+
+	// Compare
+	if actual.Draft != expected.Draft {
+		t.Errorf("expected draft %t, got %t", expected.Draft, actual.Draft)
+	}
+	if actual.Listed != expected.Listed {
+		t.Errorf("expected listed %t, got %t", expected.Listed, actual.Listed)
+	}
+	if actual.Title != expected.Title {
+		t.Errorf("expected title %s, got %s", expected.Title, actual.Title)
+	}
+	if actual.Slug != expected.Slug {
+		t.Errorf("expected slug %s, got %s", expected.Slug, actual.Slug)
+	}
+	if actual.Content != expected.Content {
+		t.Errorf("expected content %s, got %s", expected.Content, actual.Content)
+	}
+	for i := range expected.Tags {
+		if actual.Tags[i] != expected.Tags[i] {
+			t.Errorf("expected tag %s, got %s", expected.Tags[i], actual.Tags[i])
+		}
+	}
+	if actual.Published.Unix() != expected.Published.Unix() {
+		t.Errorf("expected published %s, got %s", expected.Published, actual.Published)
+	}
+}
+
+func testGetPosts(t *testing.T, service repo.Store) {
+	// Add first post
+	expected := types.Post{
+		ID:                 "",
+		Draft:              false,
+		Listed:             true,
+		Title:              "test title",
+		Slug:               testSlug(),
+		Content:            "#test content",
+		ContentHTML:        "<h1>test content</h1>",
+		ContentHTMLPreview: "<h1>test content</h1>",
+		Tags:               []string{"test", "tag"},
+		Published:          time.Now(),
+	}
+	first, err := service.AddPost(expected)
+	if err != nil {
+		t.Errorf("failed to add post; %s", err)
+	}
+
+	// Add second post, adding one hour to 'published' time
+	expected.Published = expected.Published.Add(time.Hour)
+	expected.Slug = testSlug()
+	second, err := service.AddPost(expected)
+	if err != nil {
+		t.Errorf("failed to add post; %s", err)
+	}
+
+	// Read all posts
+	posts, err := service.GetPosts(repo.PostFilters{})
+	if err != nil {
+		t.Errorf("failed to get posts; %s", err)
+	}
+
+	// Delete newly created posts
+	err = service.DeletePost(first)
+	if err != nil {
+		t.Errorf("failed to delete post; %s", err)
+	}
+	err = service.DeletePost(second)
+	if err != nil {
+		t.Errorf("failed to delete post; %s", err)
+	}
+
+	// Validate results contain at least two posts
+	if len(posts) < 2 {
+		t.Errorf("expected at least two posts, got %d", len(posts))
+	}
+
+	// Validate newly created posts are in the result
+	foundFirst := false
+	foundSecond := false
+	for _, post := range posts {
+		if post.ID == first {
+			foundFirst = true
+		}
+		if post.ID == second {
+			foundSecond = true
+		}
+	}
+	if !foundFirst {
+		t.Errorf("expected to find post: %s", first)
+	}
+	if !foundSecond {
+		t.Errorf("expected to find post: %s", second)
+	}
+
+	// Validate second post appears before first
+	for _, post := range posts {
+		if post.ID == second {
+			break
+		}
+		if post.ID == first {
+			t.Errorf("expected second post to appear before first")
+		}
+	}
+}
+
+func testGetPostsWithFilters(t *testing.T, service repo.Store) {
+	// Add 'unlisted' post
+	unlisted := types.Post{
+		ID:                 "",
+		Draft:              false,
+		Listed:             false,
+		Title:              "test title",
+		Slug:               testSlug(),
+		Content:            "#test content",
+		ContentHTML:        "<h1>test content</h1>",
+		ContentHTMLPreview: "<h1>test content</h1>",
+		Tags:               []string{"test", "tag"},
+		Published:          time.Now().Add(-time.Hour),
+	}
+	unlistedID, err := service.AddPost(unlisted)
+	if err != nil {
+		t.Errorf("failed to add post; %s", err)
+	}
+
+	// Fetch all 'listed' posts
+	listedBool := true
+	posts, err := service.GetPosts(repo.PostFilters{Listed: &listedBool})
+	if err != nil {
+		t.Errorf("failed to get posts; %s", err)
+	}
+
+	// Validate 'unlisted' post is not in the result
+	if postIn(unlistedID, posts) {
+		t.Errorf("unlisted post should not be in the result: %s", unlistedID)
+	}
+
+	// Delete 'unlisted' post
+	err = service.DeletePost(unlistedID)
+	if err != nil {
+		t.Errorf("failed to delete post; %s", err)
+	}
+
+	// Add 'draft' post
+	draft := types.Post{
+		ID:                 "",
+		Draft:              true,
+		Listed:             false,
+		Title:              "test title",
+		Slug:               testSlug(),
+		Content:            "#test content",
+		ContentHTML:        "<h1>test content</h1>",
+		ContentHTMLPreview: "<h1>test content</h1>",
+		Tags:               []string{"test", "tag"},
+		Published:          time.Now().Add(-time.Hour),
+	}
+	draftID, err := service.AddPost(draft)
+	if err != nil {
+		t.Errorf("failed to add post; %s", err)
+	}
+
+	// Fetch all 'published' posts
+	publishedBool := true
+	posts, err = service.GetPosts(repo.PostFilters{Published: &publishedBool})
+	if err != nil {
+		t.Errorf("failed to get posts; %s", err)
+	}
+
+	// Validate 'draft' post is not in the result
+	if postIn(draftID, posts) {
+		t.Errorf("draft post should not be in the result: %s", draftID)
+	}
+
+	// Delete 'draft' post
+	err = service.DeletePost(draftID)
+	if err != nil {
+		t.Errorf("failed to delete post; %s", err)
+	}
+
+	// Add 'future' post, with publish date in the future
+	future := types.Post{
+		ID:                 "",
+		Draft:              false,
+		Listed:             true,
+		Title:              "test title",
+		Slug:               testSlug(),
+		Content:            "#test content",
+		ContentHTML:        "<h1>test content</h1>",
+		ContentHTMLPreview: "<h1>test content</h1>",
+		Tags:               []string{"test", "tag"},
+		Published:          time.Now().Add(time.Hour),
+	}
+	futureID, err := service.AddPost(future)
+	if err != nil {
+		t.Errorf("failed to add post; %s", err)
+	}
+
+	// Fetch all 'published' posts
+	publishedBool = true
+	posts, err = service.GetPosts(repo.PostFilters{Published: &publishedBool})
+	if err != nil {
+		t.Errorf("failed to get posts; %s", err)
+	}
+
+	// Validate 'future' post is not in the result
+	if postIn(futureID, posts) {
+		t.Errorf("future post should not be in the result: %s", futureID)
+	}
+
+	// Delete 'future' post
+	err = service.DeletePost(futureID)
+	if err != nil {
+		t.Errorf("failed to delete post; %s", err)
+	}
+}
+
+func testGetPostBySlug(t *testing.T, service repo.Store) {
+	// Add 'draft' post
+	expected := types.Post{
+		ID:                 "",
+		Draft:              true,
+		Listed:             true,
+		Title:              "test title",
+		Slug:               testSlug(),
+		Content:            "#test content",
+		ContentHTML:        "<h1>test content</h1>",
+		ContentHTMLPreview: "<h1>test content</h1>",
+		Tags:               []string{"test", "tag"},
+		Published:          time.Now().Add(-time.Hour),
+	}
+	id, err := service.AddPost(expected)
+	if err != nil {
+		t.Errorf("failed to add post; %s", err)
+	}
+
+	// Read post by slug
+	actual, err := service.GetPostBySlug(expected.Slug, repo.PostFilters{})
+	if err != nil {
+		t.Errorf("failed to get post by slug; %s", err)
+	}
+	if actual.ID != id {
+		t.Errorf("expected post %s, got %s", id, actual.ID)
+	}
+
+	// Read post by slug, filtering to published posts
+	publishedBool := true
+	_, err = service.GetPostBySlug(expected.Slug, repo.PostFilters{Published: &publishedBool})
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected draft post to be hidden, got %v", err)
+	}
+
+	// Delete post
+	err = service.DeletePost(id)
+	if err != nil {
+		t.Errorf("failed to delete post; %s", err)
+	}
+}
+
+func testSlugs(t *testing.T, service repo.Store) {
+	// Add post without a slug
+	suffix := uuid.New().String()
+	title := "Test Title " + suffix
+	post := types.Post{
+		ID:        "",
+		Title:     title,
+		Slug:      "",
+		Content:   "#test content",
+		Tags:      []string{},
+		Published: time.Now(),
+	}
+	firstID, err := service.AddPost(post)
+	if err != nil {
+		t.Errorf("failed to add post; %s", err)
+	}
+
+	// Validate slug was generated from title
+	first, err := service.GetPost(firstID)
+	if err != nil {
+		t.Errorf("failed to get post; %s", err)
+	}
+	if first.Slug != "test-title-"+suffix {
+		t.Errorf("expected slug %s, got %s", "test-title-"+suffix, first.Slug)
+	}
+
+	// Add post with the same title, which should generate a duplicate slug
+	_, err = service.AddPost(post)
+	if !errors.Is(err, repo.ErrConflict) {
+		t.Errorf("expected conflict adding duplicate slug, got %v", err)
+	}
+
+	// Add post with a distinct slug, then update it to the first post's slug
+	post.Slug = testSlug()
+	secondID, err := service.AddPost(post)
+	if err != nil {
+		t.Errorf("failed to add post; %s", err)
+	}
+	post.ID = secondID
+	post.Slug = first.Slug
+	_, err = service.UpdatePost(post)
+	if !errors.Is(err, repo.ErrConflict) {
+		t.Errorf("expected conflict updating to duplicate slug, got %v", err)
+	}
+
+	// Updating a post without changing its slug is not a conflict
+	first.Content = "#updated content"
+	_, err = service.UpdatePost(first)
+	if err != nil {
+		t.Errorf("failed to update post; %s", err)
+	}
+
+	// Delete posts
+	err = service.DeletePost(firstID)
+	if err != nil {
+		t.Errorf("failed to delete post; %s", err)
+	}
+	err = service.DeletePost(secondID)
+	if err != nil {
+		t.Errorf("failed to delete post; %s", err)
+	}
+}
+
+func testPostFilters(t *testing.T, service repo.Store) {
+	// Add a post for every combination of draft, listed, and past/future published dates
+	ids := make(map[string]string)
+	for _, draft := range []bool{true, false} {
+		for _, listed := range []bool{true, false} {
+			for _, future := range []bool{true, false} {
+				post := types.Post{
+					ID:        "",
+					Draft:     draft,
+					Listed:    listed,
+					Title:     "test title",
+					Slug:      testSlug(),
+					Content:   "#test content",
+					Tags:      []string{},
+					Published: time.Now().Add(-time.Hour),
+				}
+				if future {
+					post.Published = time.Now().Add(time.Hour)
+				}
+				id, err := service.AddPost(post)
+				if err != nil {
+					t.Errorf("failed to add post; %s", err)
+				}
+				ids[kind(draft, listed, future)] = id
+			}
+		}
+	}
+
+	// Posts expected for each combination of filters. A post is published if it is
+	// not a draft and its published date is not in the future.
+	t1 := true
+	f1 := false
+	cases := []struct {
+		filters  repo.PostFilters
+		expected []string
+	}{
+		{repo.PostFilters{}, []string{"past", "past-unlisted", "past-draft", "past-draft-unlisted", "future", "future-unlisted", "future-draft", "future-draft-unlisted"}},
+		{repo.PostFilters{Listed: &t1}, []string{"past", "past-draft", "future", "future-draft"}},
+		{repo.PostFilters{Listed: &f1}, []string{"past-unlisted", "past-draft-unlisted", "future-unlisted", "future-draft-unlisted"}},
+		{repo.PostFilters{Published: &t1}, []string{"past", "past-unlisted"}},
+		{repo.PostFilters{Published: &f1}, []string{"past-draft", "past-draft-unlisted", "future", "future-unlisted", "future-draft", "future-draft-unlisted"}},
+		{repo.PostFilters{Listed: &t1, Published: &t1}, []string{"past"}},
+		{repo.PostFilters{Listed: &t1, Published: &f1}, []string{"past-draft", "future", "future-draft"}},
+		{repo.PostFilters{Listed: &f1, Published: &t1}, []string{"past-unlisted"}},
+		{repo.PostFilters{Listed: &f1, Published: &f1}, []string{"past-draft-unlisted", "future-unlisted", "future-draft-unlisted"}},
+	}
+
+	for _, tc := range cases {
+		posts, err := service.GetPosts(tc.filters)
+		if err != nil {
+			t.Errorf("failed to get posts; %s", err)
+		}
+		for k, id := range ids {
+			if expected := slices.Contains(tc.expected, k); postIn(id, posts) != expected {
+				t.Errorf("expected '%s' post in result to be %t for filters %s", k, expected, describe(tc.filters))
+			}
+		}
+	}
+
+	// Delete posts
+	for _, id := range ids {
+		err := service.DeletePost(id)
+		if err != nil {
+			t.Errorf("failed to delete post; %s", err)
+		}
+	}
+}
+
+func testUpdatePost(t *testing.T, service repo.Store) {
+	// Create post
+	post := types.Post{
+		ID:                 "",
+		Draft:              false,
+		Listed:             true,
+		Title:              "test title",
+		Slug:               testSlug(),
+		Content:            "#test content",
+		ContentHTML:        "<h1>test content</h1>",
+		ContentHTMLPreview: "<h1>test content</h1>",
+		Tags:               []string{"test", "tag"},
+		Published:          time.Now(),
+	}
+	postID, err := service.AddPost(post)
+	if err != nil {
+		t.Errorf("failed to add post; %s", err)
+	}
+
+	// Update post
+	post.ID = postID
+	post.Title = "updated title"
+	post.Slug = "updated-title"
+	post.Content = "#updated content"
+	post.Tags = []string{}
+
+	_, err = service.UpdatePost(post)
+	if err != nil {
+		t.Errorf("failed to update post; %s", err)
+	}
+
+	// Read post
+	actual, err := service.GetPost(postID)
+	if err != nil {
+		t.Errorf("failed to get post; %s", err)
+	}
+
+	// Delete post
+	err = service.DeletePost(postID)
+	if err != nil {
+		t.Errorf("failed to delete post; %s", err)
+	}
+
+	// Compare
+	if actual.Draft != post.Draft {
+		t.Errorf("expected draft %t, got %t", post.Draft, actual.Draft)
+	}
+	if actual.Listed != post.Listed {
+		t.Errorf("expected listed %t, got %t", post.Listed, actual.Listed)
+	}
+	if actual.Title != post.Title {
+		t.Errorf("expected title %s, got %s", post.Title, actual.Title)
+	}
+	if actual.Slug != post.Slug {
+		t.Errorf("expected slug %s, got %s", post.Slug, actual.Slug)
+	}
+	if actual.Content != post.Content {
+		t.Errorf("expected content %s, got %s", post.Content, actual.Content)
+	}
+	if actual.ContentHTML != post.ContentHTML {
+		t.Errorf("expected content html %s, got %s", post.ContentHTML, actual.ContentHTML)
+	}
+	if actual.ContentHTMLPreview != post.ContentHTMLPreview {
+		t.Errorf("expected content html preview %s, got %s", post.ContentHTMLPreview, actual.ContentHTMLPreview)
+	}
+	if len(actual.Tags) != 0 {
+		t.Errorf("expected no tags, got %v", actual.Tags)
+	}
+	if actual.Published.Unix() != post.Published.Unix() {
+		t.Errorf("expected published %s, got %s", post.Published, actual.Published)
+	}
+}
+
+func testMissingLike(t *testing.T, service repo.Store) {
+	id := uuid.New().String()
+
+	_, err := service.GetLike(id)
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected not found getting missing like, got %v", err)
+	}
+	err = service.DeleteLike(id)
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected not found deleting missing like, got %v", err)
+	}
+	_, _, err = service.GetLikesPage(repo.Page{Cursor: "bogus"})
+	if !errors.Is(err, repo.ErrInvalid) {
+		t.Errorf("expected invalid cursor error, got %v", err)
+	}
+}
+
+func testGetPostsPage(t *testing.T, service repo.Store) {
+	// Add three posts, published in the future so they are ordered ahead of existing posts
+	base := time.Now().AddDate(100, 0, 0)
+	ids := make([]string, 3)
+	for i := range ids {
+		post := types.Post{
+			ID:        "",
+			Title:     "test title",
+			Slug:      testSlug(),
+			Content:   "#test content",
+			Tags:      []string{},
+			Published: base.Add(-time.Duration(i) * time.Hour),
+		}
+		id, err := service.AddPost(post)
+		if err != nil {
+			t.Errorf("failed to add post; %s", err)
+		}
+		ids[i] = id
+	}
+
+	// Read posts one page at a time, until the newly created posts have been seen
+	var paged []types.Post
+	page := repo.Page{Limit: 1}
+	for len(paged) < len(ids) {
+		posts, next, err := service.GetPostsPage(repo.PostFilters{}, page)
+		if err != nil {
+			t.Errorf("failed to get posts; %s", err)
+			break
+		}
+		if len(posts) != 1 {
+			t.Errorf("expected page of 1 post, got %d", len(posts))
+		}
+		paged = append(paged, posts...)
+		if next == "" {
+			break
+		}
+		page.Cursor = next
+	}
+
+	// Validate the pages contained the newly created posts, in order
+	for i, id := range ids {
+		if i >= len(paged) || paged[i].ID != id {
+			t.Errorf("expected post %d to be %s", i, id)
+		}
+	}
+
+	// Invalid cursors are rejected
+	_, _, err := service.GetPostsPage(repo.PostFilters{}, repo.Page{Cursor: "bogus"})
+	if !errors.Is(err, repo.ErrInvalid) {
+		t.Errorf("expected invalid cursor error, got %v", err)
+	}
+
+	// Delete posts
+	for _, id := range ids {
+		err = service.DeletePost(id)
+		if err != nil {
+			t.Errorf("failed to delete post; %s", err)
+		}
+	}
+}
+
+func testConditionalUpdate(t *testing.T, service repo.Store) {
+	postID, err := service.AddPost(types.Post{Title: "test title", Slug: testSlug(), Published: time.Now()})
+	if err != nil {
+		t.Fatalf("failed to add post; %s", err)
+	}
+	defer service.DeletePost(postID)
+
+	post, err := service.GetPost(postID)
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+	if post.Updated.IsZero() {
+		t.Errorf("expected update time to be set")
+	}
+
+	// Update based on the current version
+	stale := post.Updated
+	post.Title = "updated title"
+	updated, err := service.UpdatePost(post)
+	if err != nil {
+		t.Fatalf("failed to update post; %s", err)
+	}
+	if !updated.After(stale) {
+		t.Errorf("expected update time after %s, got %s", stale, updated)
+	}
+	actual, err := service.GetPost(postID)
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+	if !actual.Updated.Equal(updated) {
+		t.Errorf("expected update time %s, got %s", updated, actual.Updated)
+	}
+
+	// Update based on the previous version
+	post.Updated = stale
+	post.Title = "stale title"
+	_, err = service.UpdatePost(post)
+	if !errors.Is(err, repo.ErrPreconditionFailed) {
+		t.Errorf("expected precondition failure updating stale post, got %v", err)
+	}
+	actual, err = service.GetPost(postID)
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+	if actual.Title != "updated title" {
+		t.Errorf("expected title 'updated title', got %s", actual.Title)
+	}
+
+	// Unconditional updates always apply
+	post.Updated = time.Time{}
+	_, err = service.UpdatePost(post)
+	if err != nil {
+		t.Errorf("failed to update post; %s", err)
+	}
+}
+
+func testPatchPost(t *testing.T, service repo.Store) {
+	post := types.Post{
+		Listed:    false,
+		Title:     "test title",
+		Slug:      testSlug(),
+		Content:   "#test content",
+		Tags:      []string{"test", "tag"},
+		Published: time.Now(),
+	}
+	postID, err := service.AddPost(post)
+	if err != nil {
+		t.Fatalf("failed to add post; %s", err)
+	}
+	defer service.DeletePost(postID)
+	stored, err := service.GetPost(postID)
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+
+	// Patch a single field, leaving the rest untouched
+	patched, err := service.PatchPost(types.Post{ID: postID, Listed: true, Title: "ignored"}, []string{"listed"})
+	if err != nil {
+		t.Fatalf("failed to patch post; %s", err)
+	}
+	actual, err := service.GetPost(postID)
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+	if !actual.Listed {
+		t.Errorf("expected listed to be patched")
+	}
+	if actual.Title != post.Title || actual.Content != post.Content || actual.Slug != post.Slug {
+		t.Errorf("expected unpatched fields to be unchanged, got %+v", actual)
+	}
+	if !slices.Equal(actual.Tags, post.Tags) {
+		t.Errorf("expected tags %v, got %v", post.Tags, actual.Tags)
+	}
+	if !patched.Listed || patched.Title != post.Title || !patched.Updated.Equal(actual.Updated) {
+		t.Errorf("expected patched post to match stored post, got %+v", patched)
+	}
+
+	// Patching the slug to be empty generates one from the title
+	_, err = service.PatchPost(types.Post{ID: postID, Title: "test title " + postID}, []string{"slug", "title"})
+	if err != nil {
+		t.Fatalf("failed to patch post; %s", err)
+	}
+	actual, err = service.GetPost(postID)
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+	if actual.Slug != "test-title-"+postID {
+		t.Errorf("expected slug 'test-title-%s', got %s", postID, actual.Slug)
+	}
+
+	// Patches based on a previous version fail
+	_, err = service.PatchPost(types.Post{ID: postID, Updated: stored.Updated}, []string{"draft"})
+	if !errors.Is(err, repo.ErrPreconditionFailed) {
+		t.Errorf("expected precondition failure patching stale post, got %v", err)
+	}
+
+	// Read-only and unknown fields can't be patched
+	for _, fields := range [][]string{{"id"}, {"updated"}, {"bogus"}, {}} {
+		_, err = service.PatchPost(types.Post{ID: postID}, fields)
+		if !errors.Is(err, repo.ErrInvalid) {
+			t.Errorf("expected invalid patching fields %v, got %v", fields, err)
+		}
+	}
+
+	// Patched slugs must be unique
+	otherID, err := service.AddPost(types.Post{Title: "test title", Slug: testSlug(), Published: time.Now()})
+	if err != nil {
+		t.Fatalf("failed to add post; %s", err)
+	}
+	defer service.DeletePost(otherID)
+	_, err = service.PatchPost(types.Post{ID: otherID, Slug: actual.Slug}, []string{"slug"})
+	if !errors.Is(err, repo.ErrConflict) {
+		t.Errorf("expected conflict patching to duplicate slug, got %v", err)
+	}
+
+	// Missing posts can't be patched
+	_, err = service.PatchPost(types.Post{ID: uuid.New().String()}, []string{"listed"})
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected not found patching missing post, got %v", err)
+	}
+}
+
+func testRevisions(t *testing.T, service repo.Store) {
+	post := types.Post{
+		Title:     "test title",
+		Slug:      testSlug(),
+		Content:   "first content",
+		Tags:      []string{"test", "tag"},
+		Published: time.Now(),
+	}
+	postID, err := service.AddPost(post)
+	if err != nil {
+		t.Fatalf("failed to add post; %s", err)
+	}
+	first, err := service.GetPost(postID)
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+
+	// New posts have no revisions
+	revisions, next, err := service.GetRevisions(postID, repo.Page{})
+	if err != nil {
+		t.Fatalf("failed to get revisions; %s", err)
+	}
+	if len(revisions) != 0 || next != "" {
+		t.Errorf("expected no revisions, got %d", len(revisions))
+	}
+
+	// Both updates and patches keep the previous version
+	second := first
+	second.Content = "second content"
+	second.Updated = time.Time{}
+	if _, err := service.UpdatePost(second); err != nil {
+		t.Fatalf("failed to update post; %s", err)
+	}
+	if _, err := service.PatchPost(types.Post{ID: postID, Content: "third content"}, []string{"content"}); err != nil {
+		t.Fatalf("failed to patch post; %s", err)
+	}
+
+	revisions, next, err = service.GetRevisions(postID, repo.Page{})
+	if err != nil {
+		t.Fatalf("failed to get revisions; %s", err)
+	}
+	if len(revisions) != 2 || next != "" {
+		t.Fatalf("expected 2 revisions, got %d", len(revisions))
+	}
+	if revisions[0].Created.Before(revisions[1].Created) {
+		t.Errorf("expected revision created %s after %s", revisions[0].Created, revisions[1].Created)
+	}
+
+	// Listed revisions describe the post as it was
+	if revisions[1].Title != first.Title || revisions[1].Slug != first.Slug || !revisions[1].Updated.Equal(first.Updated) {
+		t.Errorf("expected revision of post %+v, got %+v", first, revisions[1])
+	}
+
+	// Revisions are paged, most recent first
+	page, next, err := service.GetRevisions(postID, repo.Page{Limit: 1})
+	if err != nil {
+		t.Fatalf("failed to get revisions; %s", err)
+	}
+	if len(page) != 1 || page[0].ID != revisions[0].ID || next == "" {
+		t.Errorf("expected first page to contain %s with a cursor, got %v, %q", revisions[0].ID, page, next)
+	}
+	page, next, err = service.GetRevisions(postID, repo.Page{Limit: 1, Cursor: next})
+	if err != nil {
+		t.Fatalf("failed to get revisions; %s", err)
+	}
+	if len(page) != 1 || page[0].ID != revisions[1].ID || next != "" {
+		t.Errorf("expected last page to contain %s, got %v, %q", revisions[1].ID, page, next)
+	}
+
+	// Revisions hold the whole post as it was
+	latest, err := service.GetRevision(postID, revisions[0].ID)
+	if err != nil {
+		t.Fatalf("failed to get revision; %s", err)
+	}
+	if latest.Post.Content != "second content" {
+		t.Errorf("expected content %q, got %q", "second content", latest.Post.Content)
+	}
+	revision, err := service.GetRevision(postID, revisions[1].ID)
+	if err != nil {
+		t.Fatalf("failed to get revision; %s", err)
+	}
+	if revision.ID != revisions[1].ID || revision.Post.Content != "first content" {
+		t.Errorf("expected revision %s of first content, got %+v", revisions[1].ID, revision)
+	}
+	if revision.Post.ID != postID || revision.Post.Title != first.Title || revision.Post.Slug != first.Slug {
+		t.Errorf("expected revision of post %+v, got %+v", first, revision.Post)
+	}
+	if !slices.Equal(revision.Post.Tags, first.Tags) {
+		t.Errorf("expected tags %v, got %v", first.Tags, revision.Post.Tags)
+	}
+	if !revision.Post.Updated.Equal(first.Updated) {
+		t.Errorf("expected update time %s, got %s", first.Updated, revision.Post.Updated)
+	}
+
+	// Missing revisions
+	_, err = service.GetRevision(postID, uuid.New().String())
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected not found getting missing revision, got %v", err)
+	}
+
+	// Revisions are deleted with their post
+	if err := service.DeletePost(postID); err != nil {
+		t.Fatalf("failed to delete post; %s", err)
+	}
+	_, _, err = service.GetRevisions(postID, repo.Page{})
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected not found getting revisions of deleted post, got %v", err)
+	}
+	_, err = service.GetRevision(postID, revisions[0].ID)
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected not found getting revision of deleted post, got %v", err)
+	}
+}
+
+// testDeleteManyRevisions deletes a post with more revisions than Firestore allows writes in a single commit.
+func testDeleteManyRevisions(t *testing.T, service repo.Store) {
+	postID, err := service.AddPost(types.Post{Title: "test title", Slug: testSlug(), Content: "content 0", Published: time.Now()})
+	if err != nil {
+		t.Fatalf("failed to add post; %s", err)
+	}
+	for i := 1; i <= 501; i++ {
+		if _, err := service.PatchPost(types.Post{ID: postID, Content: fmt.Sprintf("content %d", i)}, []string{"content"}); err != nil {
+			t.Fatalf("failed to patch post; %s", err)
+		}
+	}
+
+	revisions, _, err := service.GetRevisions(postID, repo.Page{Limit: 1})
+	if err != nil || len(revisions) != 1 {
+		t.Fatalf("failed to get revisions; %v", err)
+	}
+
+	if err := service.DeletePost(postID); err != nil {
+		t.Fatalf("failed to delete post; %s", err)
+	}
+	_, err = service.GetPost(postID)
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected not found getting deleted post, got %v", err)
+	}
+	_, err = service.GetRevision(postID, revisions[0].ID)
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected not found getting revision of deleted post, got %v", err)
+	}
+}
+
+func testMetadata(t *testing.T, service repo.Store) {
+	postID, err := service.AddPost(types.Post{
+		Title:     "test title",
+		Slug:      testSlug(),
+		Content:   "# Heading\n\nSome *test* content",
+		Published: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("failed to add post; %s", err)
+	}
+	defer service.DeletePost(postID)
+
+	// Metadata is derived when a post is added
+	actual, err := service.GetPost(postID)
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+	if actual.WordCount != 4 || actual.ReadingTimeMinutes != 1 || actual.Excerpt != "Heading Some test content" {
+		t.Errorf("expected metadata of added post, got %d words, %d minutes, excerpt '%s'", actual.WordCount, actual.ReadingTimeMinutes, actual.Excerpt)
+	}
+
+	// Metadata sent by clients is replaced
+	actual.Content = "Updated content"
+	actual.WordCount = 100
+	actual.Excerpt = "ignored"
+	actual.Updated = time.Time{}
+	if _, err := service.UpdatePost(actual); err != nil {
+		t.Fatalf("failed to update post; %s", err)
+	}
+	actual, err = service.GetPost(postID)
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+	if actual.WordCount != 2 || actual.Excerpt != "Updated content" {
+		t.Errorf("expected metadata of updated post, got %d words, excerpt '%s'", actual.WordCount, actual.Excerpt)
+	}
+
+	// Patching content derives its metadata again, while patching other fields leaves it untouched
+	patched, err := service.PatchPost(types.Post{ID: postID, Content: "Patched content, in five words"}, []string{"content"})
+	if err != nil {
+		t.Fatalf("failed to patch post; %s", err)
+	}
+	if patched.WordCount != 5 || patched.Excerpt != "Patched content, in five words" {
+		t.Errorf("expected metadata of patched post, got %d words, excerpt '%s'", patched.WordCount, patched.Excerpt)
+	}
+	if _, err := service.PatchPost(types.Post{ID: postID, Listed: true}, []string{"listed"}); err != nil {
+		t.Fatalf("failed to patch post; %s", err)
+	}
+	actual, err = service.GetPost(postID)
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+	if actual.WordCount != 5 || actual.ReadingTimeMinutes != 1 || actual.Excerpt != patched.Excerpt {
+		t.Errorf("expected metadata to be unchanged, got %d words, %d minutes, excerpt '%s'", actual.WordCount, actual.ReadingTimeMinutes, actual.Excerpt)
+	}
+}
+
+func testPutLike(t *testing.T, service repo.Store) {
+	like := types.Like{
+		ID:        uuid.New().String(),
+		Timestamp: time.Now(),
+		Title:     "test title",
+		URL:       "http://test.com",
+	}
+	defer service.DeleteLike(like.ID)
+
+	// Putting a new like creates it with its ID
+	if err := service.PutLike(like); err != nil {
+		t.Fatalf("failed to put like; %s", err)
+	}
+	actual, err := service.GetLike(like.ID)
+	if err != nil {
+		t.Fatalf("failed to get like; %s", err)
+	}
+	if actual.Title != like.Title || actual.Timestamp.Unix() != like.Timestamp.Unix() {
+		t.Errorf("expected like %+v, got %+v", like, actual)
+	}
+
+	// Putting an existing like replaces it
+	like.Title = "updated title"
+	if err := service.PutLike(like); err != nil {
+		t.Fatalf("failed to put like; %s", err)
+	}
+	actual, err = service.GetLike(like.ID)
+	if err != nil {
+		t.Fatalf("failed to get like; %s", err)
+	}
+	if actual.Title != like.Title {
+		t.Errorf("expected title %s, got %s", like.Title, actual.Title)
+	}
+
+	// Likes must have an ID
+	if err := service.PutLike(types.Like{Title: "test title"}); !errors.Is(err, repo.ErrInvalid) {
+		t.Errorf("expected invalid putting like without id, got %v", err)
+	}
+}
+
+func testPutPost(t *testing.T, service repo.Store) {
+	post := types.Post{
+		ID:        uuid.New().String(),
+		Title:     "test title",
+		Slug:      testSlug(),
+		Content:   "test content",
+		Tags:      []string{"test"},
+		Published: time.Now(),
+	}
+	defer service.DeletePost(post.ID)
+
+	// Putting a new post creates it with its ID
+	if err := service.PutPost(post); err != nil {
+		t.Fatalf("failed to put post; %s", err)
+	}
+	actual, err := service.GetPost(post.ID)
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+	if actual.Title != post.Title || actual.Slug != post.Slug || actual.WordCount != 2 {
+		t.Errorf("expected post %+v, got %+v", post, actual)
+	}
+	revisions, _, err := service.GetRevisions(post.ID, repo.Page{})
+	if err != nil {
+		t.Fatalf("failed to get revisions; %s", err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("expected no revisions of new post, got %d", len(revisions))
+	}
+
+	// Putting an existing post replaces it, keeping the previous version as a revision
+	post.Title = "updated title"
+	if err := service.PutPost(post); err != nil {
+		t.Fatalf("failed to put post; %s", err)
+	}
+	actual, err = service.GetPost(post.ID)
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+	if actual.Title != post.Title {
+		t.Errorf("expected title %s, got %s", post.Title, actual.Title)
+	}
+	revisions, _, err = service.GetRevisions(post.ID, repo.Page{})
+	if err != nil {
+		t.Fatalf("failed to get revisions; %s", err)
+	}
+	if len(revisions) != 1 || revisions[0].Title != "test title" {
+		t.Errorf("expected revision of previous version, got %+v", revisions)
+	}
+
+	// Slugs must be unique
+	other := post
+	other.ID = uuid.New().String()
+	defer service.DeletePost(other.ID)
+	if err := service.PutPost(other); !errors.Is(err, repo.ErrConflict) {
+		t.Errorf("expected conflict putting post with duplicate slug, got %v", err)
+	}
+	if _, err := service.GetPost(other.ID); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected conflicting post not to be created, got %v", err)
+	}
+
+	// Posts must have an ID
+	post.ID = ""
+	if err := service.PutPost(post); !errors.Is(err, repo.ErrInvalid) {
+		t.Errorf("expected invalid putting post without id, got %v", err)
+	}
+}
+
+func testMissingPost(t *testing.T, service repo.Store) {
+	id := uuid.New().String()
+
+	_, err := service.GetPost(id)
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected not found getting missing post, got %v", err)
+	}
+	_, err = service.UpdatePost(types.Post{ID: id, Title: "test title", Slug: testSlug()})
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected not found updating missing post, got %v", err)
+	}
+	err = service.DeletePost(id)
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected not found deleting missing post, got %v", err)
+	}
+	_, err = service.GetPostBySlug(testSlug(), repo.PostFilters{})
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("expected not found getting missing slug, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backoff configures how failed reads are retried.
+type backoff struct {
+	attempts int
+	initial  time.Duration
+	max      time.Duration
+}
+
+var defaultBackoff = backoff{
+	attempts: 4,
+	initial:  100 * time.Millisecond,
+	max:      2 * time.Second,
+}
+
+// retry calls fn until it succeeds, returns an error that is not retryable, or runs out of attempts.
+// The delay between attempts doubles each time, up to the maximum.
+func retry(ctx context.Context, b backoff, fn func() error) error {
+	return retryWhen(ctx, b, retryable, fn)
+}
+
+// retryWhen is like retry, but retries the errors that shouldRetry reports.
+func retryWhen(ctx context.Context, b backoff, shouldRetry func(error) bool, fn func() error) error {
+	delay := b.initial
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || !shouldRetry(err) || attempt >= b.attempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, b.max)
+	}
+}
+
+// retryable reports whether an error returned by Firestore is transient.
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// aborted reports whether an error means a Firestore transaction was aborted by contention with a
+// concurrent transaction, so can be run again.
+func aborted(err error) bool {
+	return status.Code(err) == codes.Aborted
+}
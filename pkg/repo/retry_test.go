@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetry(t *testing.T) {
+	b := backoff{attempts: 3, initial: time.Millisecond, max: time.Millisecond}
+
+	// ==================== Test case 1: Retryable codes are retried ====================
+	for _, code := range []codes.Code{codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded} {
+		calls := 0
+		err := retry(context.Background(), b, func() error {
+			calls++
+			if calls < 3 {
+				return status.Error(code, "ope")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("expected %s to succeed after retries, got %v", code, err)
+		}
+		if calls != 3 {
+			t.Errorf("expected 3 calls for %s, got %d", code, calls)
+		}
+	}
+
+	// ==================== Test case 2: Other errors are not retried ====================
+	for _, err := range []error{status.Error(codes.NotFound, "ope"), errors.New("ope")} {
+		calls := 0
+		actual := retry(context.Background(), b, func() error {
+			calls++
+			return err
+		})
+		if !errors.Is(actual, err) {
+			t.Errorf("expected error %v, got %v", err, actual)
+		}
+		if calls != 1 {
+			t.Errorf("expected 1 call for %v, got %d", err, calls)
+		}
+	}
+
+	// ==================== Test case 3: Cancelled context stops retries ====================
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	err := retry(ctx, backoff{attempts: 3, initial: time.Hour, max: time.Hour}, func() error {
+		calls++
+		return status.Error(codes.Unavailable, "ope")
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected unavailable error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestRetryAborted(t *testing.T) {
+	b := backoff{attempts: 3, initial: time.Millisecond, max: time.Millisecond}
+
+	// ==================== Test case 1: Aborted transactions are run again ====================
+	calls := 0
+	err := retryWhen(context.Background(), b, aborted, func() error {
+		calls++
+		if calls < 3 {
+			return wrapErr(status.Error(codes.Aborted, "ope"), "failed to commit transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("expected aborted transaction to succeed after retries, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+
+	// ==================== Test case 2: Conflicts are not retried ====================
+	calls = 0
+	err = retryWhen(context.Background(), b, aborted, func() error {
+		calls++
+		return fmt.Errorf("slug 'test' already in use; %w", ErrConflict)
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("expected conflict, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
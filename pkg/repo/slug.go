@@ -0,0 +1,46 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/georgemblack/web-api/pkg/types"
+	"golang.org/x/text/unicode/norm"
+)
+
+// slugify converts a title into a URL-safe slug, i.e. 'Hello, Wörld!' becomes 'hello-world'.
+// Accents are stripped, and any run of characters other than ASCII letters and digits is
+// collapsed into a single hyphen.
+func slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range norm.NFKD.String(title) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		r = unicode.ToLower(r)
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
+
+// postSlug returns the post's slug, or one generated from its title if the slug is empty.
+func postSlug(post types.Post) (string, error) {
+	if post.Slug != "" {
+		return post.Slug, nil
+	}
+	slug := slugify(post.Title)
+	if slug == "" {
+		return "", fmt.Errorf("failed to generate slug from title '%s'; %w", post.Title, ErrInvalid)
+	}
+	return slug, nil
+}
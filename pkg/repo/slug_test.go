@@ -0,0 +1,23 @@
+package repo
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Hello World":                 "hello-world",
+		"Hello, Wörld!":               "hello-world",
+		"  Leading and trailing  ":    "leading-and-trailing",
+		"Go 1.21 & Firestore":         "go-1-21-firestore",
+		"already-a-slug":              "already-a-slug",
+		"Crème brûlée":                "creme-brulee",
+		"!!!":                         "",
+		"":                            "",
+		"Multiple   ---   separators": "multiple-separators",
+	}
+
+	for title, expected := range cases {
+		if actual := slugify(title); actual != expected {
+			t.Errorf("expected slug '%s' for title '%s', got '%s'", expected, title, actual)
+		}
+	}
+}
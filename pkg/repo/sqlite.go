@@ -0,0 +1,621 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/types"
+	"github.com/google/uuid"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// migrations are applied in order when the database is opened. The number of migrations
+// already applied is tracked with SQLite's 'user_version' pragma, so only append to this list.
+var migrations = []string{
+	`CREATE TABLE posts (
+		id                   TEXT PRIMARY KEY,
+		draft                INTEGER NOT NULL,
+		listed               INTEGER NOT NULL,
+		title                TEXT NOT NULL,
+		slug                 TEXT NOT NULL UNIQUE,
+		content              TEXT NOT NULL,
+		content_html         TEXT NOT NULL,
+		content_html_preview TEXT NOT NULL,
+		published            INTEGER NOT NULL
+	);
+	CREATE INDEX posts_published ON posts (published DESC, id DESC);
+	CREATE TABLE post_tags (
+		post_id  TEXT NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		tag      TEXT NOT NULL,
+		PRIMARY KEY (post_id, position)
+	);
+	CREATE TABLE likes (
+		id        TEXT PRIMARY KEY,
+		timestamp INTEGER NOT NULL,
+		title     TEXT NOT NULL,
+		url       TEXT NOT NULL
+	);
+	CREATE INDEX likes_timestamp ON likes (timestamp DESC, id DESC);`,
+	`ALTER TABLE posts ADD COLUMN updated INTEGER NOT NULL DEFAULT 0;`,
+	`CREATE TABLE post_revisions (
+		id      TEXT PRIMARY KEY,
+		post_id TEXT NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+		created INTEGER NOT NULL,
+		post    TEXT NOT NULL
+	);
+	CREATE INDEX post_revisions_created ON post_revisions (post_id, created DESC, id DESC);`,
+	`ALTER TABLE posts ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE posts ADD COLUMN reading_time_minutes INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE posts ADD COLUMN excerpt TEXT NOT NULL DEFAULT '';`,
+}
+
+// postColumns selects a post, with its tags aggregated into a JSON array.
+const postColumns = `id, draft, listed, title, slug, content, content_html, content_html_preview, word_count, reading_time_minutes, excerpt, published, updated,
+	(SELECT json_group_array(tag) FROM (SELECT tag FROM post_tags WHERE post_id = posts.id ORDER BY position))`
+
+// SQLite stores posts and likes in a SQLite database file, for self-hosting without Firestore.
+// Timestamps are stored as Unix nanoseconds so they sort correctly.
+type SQLite struct {
+	db *sql.DB
+}
+
+// NewSQLiteService opens (or creates) the database at the given path, then applies any pending migrations.
+func NewSQLiteService(path string) (*SQLite, error) {
+	if path == "" {
+		return &SQLite{}, errors.New("sqlite database path is empty")
+	}
+	// Foreign keys are enabled per connection, so the pragma is set for every connection the pool opens
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		return &SQLite{}, types.WrapErr(err, "failed to open sqlite database")
+	}
+
+	// SQLite allows a single writer, so serialize access through one connection
+	db.SetMaxOpenConns(1)
+
+	s := &SQLite{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return &SQLite{}, types.WrapErr(err, "failed to migrate sqlite database")
+	}
+	return s, nil
+}
+
+// migrate applies migrations that have not yet been applied to the database.
+func (s *SQLite) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return types.WrapErr(err, "failed to read schema version")
+	}
+
+	for i := version; i < len(migrations); i++ {
+		err := s.transaction(func(tx *sql.Tx) error {
+			if _, err := tx.Exec(migrations[i]); err != nil {
+				return err
+			}
+			// Pragmas can't be parameterized
+			_, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1))
+			return err
+		})
+		if err != nil {
+			return types.WrapErr(err, fmt.Sprintf("failed to apply migration %d", i+1))
+		}
+	}
+	return nil
+}
+
+func (s *SQLite) GetLike(id string) (types.Like, error) {
+	row := s.db.QueryRow("SELECT id, timestamp, title, url FROM likes WHERE id = ?", id)
+	like, err := scanLike(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.Like{}, fmt.Errorf("failed to get like '%s'; %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return types.Like{}, types.WrapErr(err, "failed to get like")
+	}
+	return like, nil
+}
+
+func (s *SQLite) GetLikes() ([]types.Like, error) {
+	likes, _, err := s.GetLikesPage(Page{})
+	return likes, err
+}
+
+func (s *SQLite) GetLikesPage(page Page) ([]types.Like, string, error) {
+	where, args, err := pageCondition("timestamp", page)
+	if err != nil {
+		return nil, "", err
+	}
+	query := "SELECT id, timestamp, title, url FROM likes" + whereClause(where) + " ORDER BY timestamp DESC, id DESC" + limitClause(page)
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, "", types.WrapErr(err, "failed to query likes")
+	}
+	defer rows.Close()
+
+	likes := make([]types.Like, 0)
+	for rows.Next() {
+		like, err := scanLike(rows)
+		if err != nil {
+			return nil, "", types.WrapErr(err, "failed to read like")
+		}
+		likes = append(likes, like)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", types.WrapErr(err, "failed to read likes")
+	}
+
+	return paginateSlice(likes, Page{Limit: page.Limit}, func(like types.Like) (time.Time, string) {
+		return like.Timestamp, like.ID
+	})
+}
+
+func (s *SQLite) AddLike(like types.Like) (string, error) {
+	id := uuid.New().String()
+	_, err := s.db.Exec("INSERT INTO likes (id, timestamp, title, url) VALUES (?, ?, ?, ?)",
+		id, like.Timestamp.UnixNano(), like.Title, like.URL)
+	if err != nil {
+		return "", sqliteErr(err, "failed to create like")
+	}
+	return id, nil
+}
+
+func (s *SQLite) PutLike(like types.Like) error {
+	if like.ID == "" {
+		return fmt.Errorf("like has no id; %w", ErrInvalid)
+	}
+	_, err := s.db.Exec(`INSERT INTO likes (id, timestamp, title, url) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET timestamp = excluded.timestamp, title = excluded.title, url = excluded.url`,
+		like.ID, like.Timestamp.UnixNano(), like.Title, like.URL)
+	if err != nil {
+		return sqliteErr(err, "failed to put like")
+	}
+	return nil
+}
+
+func (s *SQLite) DeleteLike(id string) error {
+	result, err := s.db.Exec("DELETE FROM likes WHERE id = ?", id)
+	if err != nil {
+		return types.WrapErr(err, "failed to delete like")
+	}
+	return requireRow(result, fmt.Sprintf("failed to delete like '%s'", id))
+}
+
+func (s *SQLite) GetPost(id string) (types.Post, error) {
+	row := s.db.QueryRow("SELECT "+postColumns+" FROM posts WHERE id = ?", id)
+	post, err := scanPost(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.Post{}, fmt.Errorf("failed to get post '%s'; %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return types.Post{}, types.WrapErr(err, "failed to get post")
+	}
+	return post, nil
+}
+
+func (s *SQLite) GetPosts(filters PostFilters) ([]types.Post, error) {
+	posts, _, err := s.GetPostsPage(filters, Page{})
+	return posts, err
+}
+
+func (s *SQLite) GetPostsPage(filters PostFilters, page Page) ([]types.Post, string, error) {
+	where, args := postCondition(filters, time.Now())
+	cursorWhere, cursorArgs, err := pageCondition("published", page)
+	if err != nil {
+		return nil, "", err
+	}
+	where = append(where, cursorWhere...)
+	args = append(args, cursorArgs...)
+
+	query := "SELECT " + postColumns + " FROM posts" + whereClause(where) + " ORDER BY published DESC, id DESC" + limitClause(page)
+	posts, err := s.queryPosts(query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return paginateSlice(posts, Page{Limit: page.Limit}, func(post types.Post) (time.Time, string) {
+		return post.Published, post.ID
+	})
+}
+
+func (s *SQLite) GetPostBySlug(slug string, filters PostFilters) (types.Post, error) {
+	where, args := postCondition(filters, time.Now())
+	where = append(where, "slug = ?")
+	args = append(args, slug)
+
+	posts, err := s.queryPosts("SELECT "+postColumns+" FROM posts"+whereClause(where)+" LIMIT 1", args...)
+	if err != nil {
+		return types.Post{}, err
+	}
+	if len(posts) == 0 {
+		return types.Post{}, fmt.Errorf("no post with slug '%s'; %w", slug, ErrNotFound)
+	}
+	return posts[0], nil
+}
+
+func (s *SQLite) AddPost(post types.Post) (string, error) {
+	post, err := preparePost(post)
+	if err != nil {
+		return "", err
+	}
+	post.ID = uuid.New().String()
+
+	err = s.transaction(func(tx *sql.Tx) error {
+		return insertPostRow(tx, post)
+	})
+	if err != nil {
+		return "", sqliteErr(err, "failed to create post")
+	}
+	return post.ID, nil
+}
+
+func (s *SQLite) PutPost(post types.Post) error {
+	if post.ID == "" {
+		return fmt.Errorf("post has no id; %w", ErrInvalid)
+	}
+	post, err := preparePost(post)
+	if err != nil {
+		return err
+	}
+
+	err = s.transaction(func(tx *sql.Tx) error {
+		current, err := scanPost(tx.QueryRow("SELECT "+postColumns+" FROM posts WHERE id = ?", post.ID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return insertPostRow(tx, post)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = updatePostRow(tx, post, current)
+		return err
+	})
+	if err != nil {
+		return sqliteErr(err, "failed to put post")
+	}
+	return nil
+}
+
+func (s *SQLite) UpdatePost(post types.Post) (time.Time, error) {
+	post, err := preparePost(post)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var updated time.Time
+	err = s.transaction(func(tx *sql.Tx) error {
+		current, err := scanPost(tx.QueryRow("SELECT "+postColumns+" FROM posts WHERE id = ?", post.ID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to update post '%s'; %w", post.ID, ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		if !post.Updated.IsZero() && !post.Updated.Equal(current.Updated) {
+			return fmt.Errorf("post '%s' has changed; %w", post.ID, ErrPreconditionFailed)
+		}
+
+		updated, err = updatePostRow(tx, post, current)
+		return err
+	})
+	if err != nil {
+		return time.Time{}, sqliteErr(err, "failed to update post")
+	}
+	return updated, nil
+}
+
+func (s *SQLite) PatchPost(patch types.Post, fields []string) (types.Post, error) {
+	var post types.Post
+	err := s.transaction(func(tx *sql.Tx) error {
+		current, err := scanPost(tx.QueryRow("SELECT "+postColumns+" FROM posts WHERE id = ?", patch.ID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to patch post '%s'; %w", patch.ID, ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		if !patch.Updated.IsZero() && !patch.Updated.Equal(current.Updated) {
+			return fmt.Errorf("post '%s' has changed; %w", patch.ID, ErrPreconditionFailed)
+		}
+
+		post, err = applyPatch(current, patch, fields)
+		if err != nil {
+			return err
+		}
+		post.Updated, err = updatePostRow(tx, post, current)
+		return err
+	})
+	if err != nil {
+		return types.Post{}, sqliteErr(err, "failed to patch post")
+	}
+	return post, nil
+}
+
+func (s *SQLite) DeletePost(id string) error {
+	result, err := s.db.Exec("DELETE FROM posts WHERE id = ?", id)
+	if err != nil {
+		return types.WrapErr(err, "failed to delete post")
+	}
+	return requireRow(result, fmt.Sprintf("failed to delete post '%s'", id))
+}
+
+// GetRevisions reads only the fields of each snapshot needed to summarize it.
+func (s *SQLite) GetRevisions(postID string, page Page) ([]types.RevisionSummary, string, error) {
+	var exists bool
+	err := s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM posts WHERE id = ?)", postID).Scan(&exists)
+	if err != nil {
+		return nil, "", types.WrapErr(err, "failed to get post")
+	}
+	if !exists {
+		return nil, "", fmt.Errorf("failed to get revisions of post '%s'; %w", postID, ErrNotFound)
+	}
+
+	where, args, err := pageCondition("created", page)
+	if err != nil {
+		return nil, "", err
+	}
+	where = append([]string{"post_id = ?"}, where...)
+	args = append([]any{postID}, args...)
+
+	query := "SELECT id, created, json_extract(post, '$.title'), json_extract(post, '$.slug'), json_extract(post, '$.updated') FROM post_revisions" +
+		whereClause(where) + " ORDER BY created DESC, id DESC" + limitClause(page)
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, "", types.WrapErr(err, "failed to query revisions")
+	}
+	defer rows.Close()
+
+	revisions := make([]types.RevisionSummary, 0)
+	for rows.Next() {
+		revision, err := scanRevisionSummary(rows)
+		if err != nil {
+			return nil, "", types.WrapErr(err, "failed to scan revision")
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", types.WrapErr(err, "failed to query revisions")
+	}
+
+	return paginateSlice(revisions, Page{Limit: page.Limit}, func(revision types.RevisionSummary) (time.Time, string) {
+		return revision.Created, revision.ID
+	})
+}
+
+func (s *SQLite) GetRevision(postID string, revisionID string) (types.Revision, error) {
+	row := s.db.QueryRow("SELECT id, created, post FROM post_revisions WHERE post_id = ? AND id = ?", postID, revisionID)
+	revision, err := scanRevision(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.Revision{}, fmt.Errorf("failed to get revision '%s' of post '%s'; %w", revisionID, postID, ErrNotFound)
+	}
+	if err != nil {
+		return types.Revision{}, types.WrapErr(err, "failed to get revision")
+	}
+	return revision, nil
+}
+
+func (s *SQLite) Close() {
+	s.db.Close()
+}
+
+func (s *SQLite) queryPosts(query string, args ...any) ([]types.Post, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, types.WrapErr(err, "failed to query posts")
+	}
+	defer rows.Close()
+
+	posts := make([]types.Post, 0)
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, types.WrapErr(err, "failed to read post")
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, types.WrapErr(err, "failed to read posts")
+	}
+	return posts, nil
+}
+
+// transaction runs fn within a transaction, committing if it succeeds and rolling back otherwise.
+func (s *SQLite) transaction(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return types.WrapErr(err, "failed to begin transaction")
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertPostRow inserts a new post's row and tags.
+func insertPostRow(tx *sql.Tx, post types.Post) error {
+	_, err := tx.Exec(`INSERT INTO posts (id, draft, listed, title, slug, content, content_html, content_html_preview, word_count, reading_time_minutes, excerpt, published, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		post.ID, post.Draft, post.Listed, post.Title, post.Slug, post.Content, post.ContentHTML, post.ContentHTMLPreview,
+		post.WordCount, post.ReadingTimeMinutes, post.Excerpt, post.Published.UnixNano(), time.Now().UnixNano())
+	if err != nil {
+		return err
+	}
+	return insertTags(tx, post)
+}
+
+// updatePostRow overwrites a post's row and tags, keeping the current version as a revision,
+// and returns its new update time. Update times must change on every write, even within the
+// clock's resolution, so the new time is always after the current one.
+func updatePostRow(tx *sql.Tx, post types.Post, current types.Post) (time.Time, error) {
+	updated := max(time.Now().UnixNano(), current.Updated.UnixNano()+1)
+	snapshot, err := json.Marshal(current)
+	if err != nil {
+		return time.Time{}, types.WrapErr(err, "failed to encode revision")
+	}
+	_, err = tx.Exec("INSERT INTO post_revisions (id, post_id, created, post) VALUES (?, ?, ?, ?)", uuid.New().String(), post.ID, updated, string(snapshot))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	_, err = tx.Exec(`UPDATE posts SET draft = ?, listed = ?, title = ?, slug = ?, content = ?, content_html = ?, content_html_preview = ?,
+		word_count = ?, reading_time_minutes = ?, excerpt = ?, published = ?, updated = ?
+		WHERE id = ?`,
+		post.Draft, post.Listed, post.Title, post.Slug, post.Content, post.ContentHTML, post.ContentHTMLPreview,
+		post.WordCount, post.ReadingTimeMinutes, post.Excerpt, post.Published.UnixNano(), updated, post.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if _, err := tx.Exec("DELETE FROM post_tags WHERE post_id = ?", post.ID); err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, updated), insertTags(tx, post)
+}
+
+func insertTags(tx *sql.Tx, post types.Post) error {
+	for i, tag := range post.Tags {
+		if _, err := tx.Exec("INSERT INTO post_tags (post_id, position, tag) VALUES (?, ?, ?)", post.ID, i, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanner is satisfied by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanLike(row scanner) (types.Like, error) {
+	var like types.Like
+	var timestamp int64
+	if err := row.Scan(&like.ID, &timestamp, &like.Title, &like.URL); err != nil {
+		return types.Like{}, err
+	}
+	like.Timestamp = time.Unix(0, timestamp)
+	return like, nil
+}
+
+func scanPost(row scanner) (types.Post, error) {
+	var post types.Post
+	var published, updated int64
+	var tags string
+	err := row.Scan(&post.ID, &post.Draft, &post.Listed, &post.Title, &post.Slug, &post.Content, &post.ContentHTML, &post.ContentHTMLPreview,
+		&post.WordCount, &post.ReadingTimeMinutes, &post.Excerpt, &published, &updated, &tags)
+	if err != nil {
+		return types.Post{}, err
+	}
+	post.Published = time.Unix(0, published)
+	post.Updated = time.Unix(0, updated)
+	if err := json.Unmarshal([]byte(tags), &post.Tags); err != nil {
+		return types.Post{}, types.WrapErr(err, "failed to parse tags")
+	}
+	return backfillMetadata(post), nil
+}
+
+func scanRevision(row scanner) (types.Revision, error) {
+	var revision types.Revision
+	var created int64
+	var snapshot string
+	if err := row.Scan(&revision.ID, &created, &snapshot); err != nil {
+		return types.Revision{}, err
+	}
+	revision.Created = time.Unix(0, created)
+	if err := json.Unmarshal([]byte(snapshot), &revision.Post); err != nil {
+		return types.Revision{}, types.WrapErr(err, "failed to parse revision")
+	}
+	revision.Post = backfillMetadata(revision.Post)
+	return revision, nil
+}
+
+func scanRevisionSummary(row scanner) (types.RevisionSummary, error) {
+	var revision types.RevisionSummary
+	var created int64
+	var updated string
+	if err := row.Scan(&revision.ID, &created, &revision.Title, &revision.Slug, &updated); err != nil {
+		return types.RevisionSummary{}, err
+	}
+	revision.Created = time.Unix(0, created)
+	// Snapshots are JSON-encoded posts, so their update time is RFC 3339
+	t, err := time.Parse(time.RFC3339Nano, updated)
+	if err != nil {
+		return types.RevisionSummary{}, types.WrapErr(err, "failed to parse revision update time")
+	}
+	revision.Updated = t
+	return revision, nil
+}
+
+// postCondition translates post filters into SQL conditions, matching PostFilters.matches.
+func postCondition(filters PostFilters, now time.Time) ([]string, []any) {
+	where := make([]string, 0)
+	args := make([]any, 0)
+	if filters.Listed != nil {
+		where = append(where, "listed = ?")
+		args = append(args, *filters.Listed)
+	}
+	if filters.Published != nil && *filters.Published {
+		where = append(where, "draft = 0 AND published <= ?")
+		args = append(args, now.UnixNano())
+	}
+	if filters.Published != nil && !*filters.Published {
+		where = append(where, "(draft = 1 OR published >= ?)")
+		args = append(args, now.UnixNano())
+	}
+	return where, args
+}
+
+// pageCondition translates a page's cursor into a SQL condition selecting results after it,
+// in descending order of the given time column and then ID.
+func pageCondition(column string, page Page) ([]string, []any, error) {
+	if page.Cursor == "" {
+		return nil, nil, nil
+	}
+	c, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	t := c.Time.UnixNano()
+	return []string{fmt.Sprintf("(%s < ? OR (%s = ? AND id < ?))", column, column)}, []any{t, t, c.ID}, nil
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// limitClause requests one more result than the page's limit, to detect whether a further page exists.
+func limitClause(page Page) string {
+	if page.Limit <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" LIMIT %d", page.Limit+1)
+}
+
+// requireRow returns ErrNotFound if a statement affected no rows.
+func requireRow(result sql.Result, message string) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return types.WrapErr(err, message)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s; %w", message, ErrNotFound)
+	}
+	return nil
+}
+
+// sqliteErr wraps an error returned by SQLite, tagging constraint violations as conflicts.
+func sqliteErr(err error, message string) error {
+	var e *sqlite.Error
+	if errors.As(err, &e) && (e.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || e.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY) {
+		return fmt.Errorf("%s; %w; %w", message, ErrConflict, err)
+	}
+	return types.WrapErr(err, message)
+}
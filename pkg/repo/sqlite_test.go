@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+func TestSQLiteMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// Create database and add a post
+	service, err := NewSQLiteService(path)
+	if err != nil {
+		t.Fatalf("failed to create sqlite service; %s", err)
+	}
+	expected := types.Post{
+		Title:     "test title",
+		Slug:      "test-title",
+		Tags:      []string{"b", "a", "c"},
+		Published: time.Now(),
+	}
+	id, err := service.AddPost(expected)
+	if err != nil {
+		t.Errorf("failed to add post; %s", err)
+	}
+	service.Close()
+
+	// Reopen database, which should not reapply migrations
+	service, err = NewSQLiteService(path)
+	if err != nil {
+		t.Fatalf("failed to reopen sqlite service; %s", err)
+	}
+	defer service.Close()
+
+	var version int
+	if err := service.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Errorf("failed to read schema version; %s", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("expected schema version %d, got %d", len(migrations), version)
+	}
+
+	// Validate post survived, with tags in order
+	actual, err := service.GetPost(id)
+	if err != nil {
+		t.Errorf("failed to get post; %s", err)
+	}
+	if len(actual.Tags) != len(expected.Tags) {
+		t.Fatalf("expected tags %v, got %v", expected.Tags, actual.Tags)
+	}
+	for i := range expected.Tags {
+		if actual.Tags[i] != expected.Tags[i] {
+			t.Errorf("expected tag %s, got %s", expected.Tags[i], actual.Tags[i])
+		}
+	}
+	if !actual.Published.Equal(expected.Published) {
+		t.Errorf("expected published %s, got %s", expected.Published, actual.Published)
+	}
+}
+
+func TestSQLiteBackfillsMetadata(t *testing.T) {
+	service, err := NewSQLiteService(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create sqlite service; %s", err)
+	}
+	defer service.Close()
+
+	// Rows written before metadata was derived hold the column defaults
+	content := "A post written before metadata was derived."
+	_, err = service.db.Exec(`INSERT INTO posts (id, draft, listed, title, slug, content, content_html, content_html_preview, published)
+		VALUES ('old', 0, 1, 'test title', 'test-title', ?, '', '', ?)`, content, time.Now().UnixNano())
+	if err != nil {
+		t.Fatalf("failed to insert post; %s", err)
+	}
+
+	post, err := service.GetPost("old")
+	if err != nil {
+		t.Fatalf("failed to get post; %s", err)
+	}
+	if post.WordCount != 7 || post.ReadingTimeMinutes != 1 || post.Excerpt != content {
+		t.Errorf("expected metadata derived from content, got %+v", post)
+	}
+}
+
+func TestSQLiteForeignKeys(t *testing.T) {
+	service, err := NewSQLiteService(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create sqlite service; %s", err)
+	}
+	defer service.Close()
+
+	// Without idle connections, each query opens a new connection, as after one is replaced
+	service.db.SetMaxIdleConns(0)
+	for i := 0; i < 2; i++ {
+		var enabled bool
+		if err := service.db.QueryRow("PRAGMA foreign_keys").Scan(&enabled); err != nil {
+			t.Fatalf("failed to read pragma; %s", err)
+		}
+		if !enabled {
+			t.Errorf("expected foreign keys enabled on connection %d", i+1)
+		}
+	}
+}
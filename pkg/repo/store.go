@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/georgemblack/web-api/pkg/conf"
+	"github.com/georgemblack/web-api/pkg/types"
+)
+
+// Store persists posts and likes.
+// Every backend must apply the same filtering, ordering, and error semantics as the Firestore backend.
+type Store interface {
+	// GetLike returns the like with the given ID, or ErrNotFound.
+	GetLike(id string) (types.Like, error)
+	// GetLikes returns all likes, most recent first.
+	GetLikes() ([]types.Like, error)
+	// GetLikesPage returns a page of likes, most recent first, along with a cursor for the next page.
+	// The cursor is empty if there are no further likes.
+	GetLikesPage(page Page) ([]types.Like, string, error)
+	// AddLike creates a like, returning its generated ID.
+	AddLike(like types.Like) (string, error)
+	// PutLike creates or replaces the like with like.ID, such as when restoring from a backup.
+	PutLike(like types.Like) error
+	// DeleteLike deletes the like with the given ID, or returns ErrNotFound.
+	DeleteLike(id string) error
+	// GetPost returns the post with the given ID, or ErrNotFound.
+	GetPost(id string) (types.Post, error)
+	// GetPosts returns posts satisfying the filters, most recently published first.
+	GetPosts(filters PostFilters) ([]types.Post, error)
+	// GetPostsPage returns a page of posts satisfying the filters, most recently published first,
+	// along with a cursor for the next page. The cursor is empty if there are no further posts.
+	GetPostsPage(filters PostFilters, page Page) ([]types.Post, string, error)
+	// GetPostBySlug returns the post with the given slug, provided it satisfies the filters.
+	GetPostBySlug(slug string, filters PostFilters) (types.Post, error)
+	// AddPost creates a post, generating a slug from its title if none is set, and returns its generated ID.
+	// Returns ErrConflict if another post already uses the slug.
+	AddPost(post types.Post) (string, error)
+	// PutPost creates or replaces the post with post.ID, such as when restoring from a backup, keeping any
+	// existing version as a revision. Returns ErrConflict if another post already uses the slug.
+	PutPost(post types.Post) error
+	// UpdatePost replaces an existing post, generating a slug from its title if none is set, keeps the
+	// previous version as a revision, and returns the new update time.
+	// If post.Updated is set, the write only succeeds if the stored post was last updated at that time,
+	// otherwise ErrPreconditionFailed is returned. Returns ErrConflict if another post already uses the slug.
+	UpdatePost(post types.Post) (time.Time, error)
+	// PatchPost updates only the named fields of an existing post, keeps the previous version as a revision,
+	// and returns the patched post. Preconditions and conflicts are as for UpdatePost.
+	PatchPost(patch types.Post, fields []string) (types.Post, error)
+	// DeletePost deletes the post with the given ID along with its revisions, or returns ErrNotFound.
+	DeletePost(id string) error
+	// GetRevisions returns a page of summaries of a post's revisions, most recent first, along with a cursor
+	// for the next page. Returns ErrNotFound if the post doesn't exist.
+	GetRevisions(postID string, page Page) ([]types.RevisionSummary, string, error)
+	// GetRevision returns a revision of a post, holding the whole post as it was, or ErrNotFound.
+	GetRevision(postID string, revisionID string) (types.Revision, error)
+	// Close releases the store's connections.
+	Close()
+}
+
+// NewStore creates the storage backend selected by the config, defaulting to Firestore.
+func NewStore(config conf.Config) (Store, error) {
+	switch config.StorageBackend {
+	case "", "firestore":
+		return NewFirestoreService(config)
+	case "memory":
+		return NewMemoryService(), nil
+	case "sqlite":
+		return NewSQLiteService(config.SQLitePath)
+	}
+	return nil, fmt.Errorf("unknown storage backend '%s'", config.StorageBackend)
+}
+
+type PostFilters struct {
+	Listed    *bool
+	Published *bool
+}
+
+// matches reports whether a post satisfies the filters at the given time.
+// Backends that cannot evaluate filters as part of a query use this directly.
+func (pf PostFilters) matches(post types.Post, now time.Time) bool {
+	// 'Listed' filter verifies the post is marked as listed
+	if pf.Listed != nil && *pf.Listed != post.Listed {
+		return false
+	}
+
+	// 'Published' filter checks:
+	//	1. Whether a post is a draft
+	//	2. Whether the post's publsihed date is in the future
+	if pf.Published != nil {
+		if *pf.Published && (post.Draft || post.Published.After(now)) {
+			return false
+		}
+		if !*pf.Published && (!post.Draft && post.Published.Before(now)) {
+			return false
+		}
+	}
+
+	return true
+}
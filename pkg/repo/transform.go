@@ -2,6 +2,7 @@ package repo
 
 import (
 	"strings"
+	"time"
 
 	"cloud.google.com/go/firestore/apiv1/firestorepb"
 	"github.com/georgemblack/web-api/pkg/types"
@@ -28,6 +29,12 @@ func likeToDoc(like types.Like) *firestorepb.Document {
 }
 
 func docToPost(doc *firestorepb.Document) types.Post {
+	// Update time is document metadata, rather than a field
+	var updated time.Time
+	if doc.UpdateTime != nil {
+		updated = doc.UpdateTime.AsTime()
+	}
+
 	// Convert tags from firestore array to string array
 	tags := doc.Fields["tags"].GetArrayValue().Values
 	tagsStr := make([]string, len(tags))
@@ -35,7 +42,7 @@ func docToPost(doc *firestorepb.Document) types.Post {
 		tagsStr[i] = v.GetStringValue()
 	}
 
-	return types.Post{
+	return backfillMetadata(types.Post{
 		ID:                 id(doc),
 		Draft:              doc.Fields["draft"].GetBooleanValue(),
 		Listed:             doc.Fields["listed"].GetBooleanValue(),
@@ -45,8 +52,12 @@ func docToPost(doc *firestorepb.Document) types.Post {
 		ContentHTML:        doc.Fields["contentHtml"].GetStringValue(),
 		ContentHTMLPreview: doc.Fields["contentHtmlPreview"].GetStringValue(),
 		Tags:               tagsStr,
+		WordCount:          int(doc.Fields["wordCount"].GetIntegerValue()),
+		ReadingTimeMinutes: int(doc.Fields["readingTimeMinutes"].GetIntegerValue()),
+		Excerpt:            doc.Fields["excerpt"].GetStringValue(),
 		Published:          doc.Fields["published"].GetTimestampValue().AsTime(),
-	}
+		Updated:            updated,
+	})
 }
 
 func postToDoc(post types.Post) *firestorepb.Document {
@@ -66,11 +77,38 @@ func postToDoc(post types.Post) *firestorepb.Document {
 			"contentHtml":        {ValueType: &firestorepb.Value_StringValue{StringValue: post.ContentHTML}},
 			"contentHtmlPreview": {ValueType: &firestorepb.Value_StringValue{StringValue: post.ContentHTMLPreview}},
 			"tags":               {ValueType: &firestorepb.Value_ArrayValue{ArrayValue: &firestorepb.ArrayValue{Values: tags}}},
+			"wordCount":          {ValueType: &firestorepb.Value_IntegerValue{IntegerValue: int64(post.WordCount)}},
+			"readingTimeMinutes": {ValueType: &firestorepb.Value_IntegerValue{IntegerValue: int64(post.ReadingTimeMinutes)}},
+			"excerpt":            {ValueType: &firestorepb.Value_StringValue{StringValue: post.Excerpt}},
 			"published":          {ValueType: &firestorepb.Value_TimestampValue{TimestampValue: timestamppb.New(post.Published)}},
 		},
 	}
 }
 
+func docToRevision(doc *firestorepb.Document) types.Revision {
+	// Revisions are nested under their post, and hold its update time as a field
+	split := strings.Split(doc.Name, "/")
+	post := docToPost(doc)
+	post.ID = split[len(split)-3]
+	post.Updated = doc.Fields["updated"].GetTimestampValue().AsTime()
+
+	return types.Revision{
+		ID:      id(doc),
+		Created: doc.Fields["created"].GetTimestampValue().AsTime(),
+		Post:    post,
+	}
+}
+
+func docToRevisionSummary(doc *firestorepb.Document) types.RevisionSummary {
+	return types.RevisionSummary{
+		ID:      id(doc),
+		Created: doc.Fields["created"].GetTimestampValue().AsTime(),
+		Title:   doc.Fields["title"].GetStringValue(),
+		Slug:    doc.Fields["slug"].GetStringValue(),
+		Updated: doc.Fields["updated"].GetTimestampValue().AsTime(),
+	}
+}
+
 func id(doc *firestorepb.Document) string {
 	split := strings.Split(doc.Name, "/")
 	return split[len(split)-1]
@@ -29,3 +29,20 @@ func NewLikes() []types.Like {
 
 	return res
 }
+
+// NewPost generates a post with random test data.
+func NewPost() types.Post {
+	return types.Post{
+		ID:                 uuid.New().String(),
+		Draft:              false,
+		Listed:             true,
+		Title:              "Post",
+		Slug:               "post",
+		Content:            "# Post",
+		ContentHTML:        "<h1>Post</h1>",
+		ContentHTMLPreview: "<h1>Post</h1>",
+		Tags:               []string{"test"},
+		Published:          time.Now(),
+		Updated:            time.Now(),
+	}
+}
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: pkg/api/api.go
+// Source: pkg/repo/store.go
 //
 // Generated by this command:
 //
-//	mockgen -source=pkg/api/api.go -destination=pkg/testutil/mocks.go -package=testutil
+//	mockgen -source=pkg/repo/store.go -destination=pkg/testutil/mocks.go -package=testutil
 //
 
 // Package testutil is a generated GoMock package.
@@ -11,37 +11,38 @@ package testutil
 
 import (
 	reflect "reflect"
+	time "time"
 
 	repo "github.com/georgemblack/web-api/pkg/repo"
 	types "github.com/georgemblack/web-api/pkg/types"
 	gomock "go.uber.org/mock/gomock"
 )
 
-// MockFirestoreService is a mock of FirestoreService interface.
-type MockFirestoreService struct {
+// MockStore is a mock of Store interface.
+type MockStore struct {
 	ctrl     *gomock.Controller
-	recorder *MockFirestoreServiceMockRecorder
+	recorder *MockStoreMockRecorder
 }
 
-// MockFirestoreServiceMockRecorder is the mock recorder for MockFirestoreService.
-type MockFirestoreServiceMockRecorder struct {
-	mock *MockFirestoreService
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
 }
 
-// NewMockFirestoreService creates a new mock instance.
-func NewMockFirestoreService(ctrl *gomock.Controller) *MockFirestoreService {
-	mock := &MockFirestoreService{ctrl: ctrl}
-	mock.recorder = &MockFirestoreServiceMockRecorder{mock}
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockFirestoreService) EXPECT() *MockFirestoreServiceMockRecorder {
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
 // AddLike mocks base method.
-func (m *MockFirestoreService) AddLike(like types.Like) (string, error) {
+func (m *MockStore) AddLike(like types.Like) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "AddLike", like)
 	ret0, _ := ret[0].(string)
@@ -50,13 +51,13 @@ func (m *MockFirestoreService) AddLike(like types.Like) (string, error) {
 }
 
 // AddLike indicates an expected call of AddLike.
-func (mr *MockFirestoreServiceMockRecorder) AddLike(like any) *gomock.Call {
+func (mr *MockStoreMockRecorder) AddLike(like any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLike", reflect.TypeOf((*MockFirestoreService)(nil).AddLike), like)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLike", reflect.TypeOf((*MockStore)(nil).AddLike), like)
 }
 
 // AddPost mocks base method.
-func (m *MockFirestoreService) AddPost(post types.Post) (string, error) {
+func (m *MockStore) AddPost(post types.Post) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "AddPost", post)
 	ret0, _ := ret[0].(string)
@@ -65,25 +66,25 @@ func (m *MockFirestoreService) AddPost(post types.Post) (string, error) {
 }
 
 // AddPost indicates an expected call of AddPost.
-func (mr *MockFirestoreServiceMockRecorder) AddPost(post any) *gomock.Call {
+func (mr *MockStoreMockRecorder) AddPost(post any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPost", reflect.TypeOf((*MockFirestoreService)(nil).AddPost), post)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPost", reflect.TypeOf((*MockStore)(nil).AddPost), post)
 }
 
 // Close mocks base method.
-func (m *MockFirestoreService) Close() {
+func (m *MockStore) Close() {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "Close")
 }
 
 // Close indicates an expected call of Close.
-func (mr *MockFirestoreServiceMockRecorder) Close() *gomock.Call {
+func (mr *MockStoreMockRecorder) Close() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockFirestoreService)(nil).Close))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockStore)(nil).Close))
 }
 
 // DeleteLike mocks base method.
-func (m *MockFirestoreService) DeleteLike(id string) error {
+func (m *MockStore) DeleteLike(id string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteLike", id)
 	ret0, _ := ret[0].(error)
@@ -91,13 +92,13 @@ func (m *MockFirestoreService) DeleteLike(id string) error {
 }
 
 // DeleteLike indicates an expected call of DeleteLike.
-func (mr *MockFirestoreServiceMockRecorder) DeleteLike(id any) *gomock.Call {
+func (mr *MockStoreMockRecorder) DeleteLike(id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLike", reflect.TypeOf((*MockFirestoreService)(nil).DeleteLike), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLike", reflect.TypeOf((*MockStore)(nil).DeleteLike), id)
 }
 
 // DeletePost mocks base method.
-func (m *MockFirestoreService) DeletePost(id string) error {
+func (m *MockStore) DeletePost(id string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeletePost", id)
 	ret0, _ := ret[0].(error)
@@ -105,13 +106,13 @@ func (m *MockFirestoreService) DeletePost(id string) error {
 }
 
 // DeletePost indicates an expected call of DeletePost.
-func (mr *MockFirestoreServiceMockRecorder) DeletePost(id any) *gomock.Call {
+func (mr *MockStoreMockRecorder) DeletePost(id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockFirestoreService)(nil).DeletePost), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockStore)(nil).DeletePost), id)
 }
 
 // GetLike mocks base method.
-func (m *MockFirestoreService) GetLike(id string) (types.Like, error) {
+func (m *MockStore) GetLike(id string) (types.Like, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetLike", id)
 	ret0, _ := ret[0].(types.Like)
@@ -120,13 +121,13 @@ func (m *MockFirestoreService) GetLike(id string) (types.Like, error) {
 }
 
 // GetLike indicates an expected call of GetLike.
-func (mr *MockFirestoreServiceMockRecorder) GetLike(id any) *gomock.Call {
+func (mr *MockStoreMockRecorder) GetLike(id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLike", reflect.TypeOf((*MockFirestoreService)(nil).GetLike), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLike", reflect.TypeOf((*MockStore)(nil).GetLike), id)
 }
 
 // GetLikes mocks base method.
-func (m *MockFirestoreService) GetLikes() ([]types.Like, error) {
+func (m *MockStore) GetLikes() ([]types.Like, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetLikes")
 	ret0, _ := ret[0].([]types.Like)
@@ -135,13 +136,29 @@ func (m *MockFirestoreService) GetLikes() ([]types.Like, error) {
 }
 
 // GetLikes indicates an expected call of GetLikes.
-func (mr *MockFirestoreServiceMockRecorder) GetLikes() *gomock.Call {
+func (mr *MockStoreMockRecorder) GetLikes() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLikes", reflect.TypeOf((*MockFirestoreService)(nil).GetLikes))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLikes", reflect.TypeOf((*MockStore)(nil).GetLikes))
+}
+
+// GetLikesPage mocks base method.
+func (m *MockStore) GetLikesPage(page repo.Page) ([]types.Like, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLikesPage", page)
+	ret0, _ := ret[0].([]types.Like)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetLikesPage indicates an expected call of GetLikesPage.
+func (mr *MockStoreMockRecorder) GetLikesPage(page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLikesPage", reflect.TypeOf((*MockStore)(nil).GetLikesPage), page)
 }
 
 // GetPost mocks base method.
-func (m *MockFirestoreService) GetPost(id string) (types.Post, error) {
+func (m *MockStore) GetPost(id string) (types.Post, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPost", id)
 	ret0, _ := ret[0].(types.Post)
@@ -150,13 +167,28 @@ func (m *MockFirestoreService) GetPost(id string) (types.Post, error) {
 }
 
 // GetPost indicates an expected call of GetPost.
-func (mr *MockFirestoreServiceMockRecorder) GetPost(id any) *gomock.Call {
+func (mr *MockStoreMockRecorder) GetPost(id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockFirestoreService)(nil).GetPost), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockStore)(nil).GetPost), id)
+}
+
+// GetPostBySlug mocks base method.
+func (m *MockStore) GetPostBySlug(slug string, filters repo.PostFilters) (types.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostBySlug", slug, filters)
+	ret0, _ := ret[0].(types.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostBySlug indicates an expected call of GetPostBySlug.
+func (mr *MockStoreMockRecorder) GetPostBySlug(slug, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostBySlug", reflect.TypeOf((*MockStore)(nil).GetPostBySlug), slug, filters)
 }
 
 // GetPosts mocks base method.
-func (m *MockFirestoreService) GetPosts(filters repo.PostFilters) ([]types.Post, error) {
+func (m *MockStore) GetPosts(filters repo.PostFilters) ([]types.Post, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPosts", filters)
 	ret0, _ := ret[0].([]types.Post)
@@ -165,21 +197,112 @@ func (m *MockFirestoreService) GetPosts(filters repo.PostFilters) ([]types.Post,
 }
 
 // GetPosts indicates an expected call of GetPosts.
-func (mr *MockFirestoreServiceMockRecorder) GetPosts(filters any) *gomock.Call {
+func (mr *MockStoreMockRecorder) GetPosts(filters any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPosts", reflect.TypeOf((*MockFirestoreService)(nil).GetPosts), filters)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPosts", reflect.TypeOf((*MockStore)(nil).GetPosts), filters)
 }
 
-// UpdatePost mocks base method.
-func (m *MockFirestoreService) UpdatePost(post types.Post) error {
+// GetPostsPage mocks base method.
+func (m *MockStore) GetPostsPage(filters repo.PostFilters, page repo.Page) ([]types.Post, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePost", post)
+	ret := m.ctrl.Call(m, "GetPostsPage", filters, page)
+	ret0, _ := ret[0].([]types.Post)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPostsPage indicates an expected call of GetPostsPage.
+func (mr *MockStoreMockRecorder) GetPostsPage(filters, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostsPage", reflect.TypeOf((*MockStore)(nil).GetPostsPage), filters, page)
+}
+
+// GetRevision mocks base method.
+func (m *MockStore) GetRevision(postID, revisionID string) (types.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", postID, revisionID)
+	ret0, _ := ret[0].(types.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockStoreMockRecorder) GetRevision(postID, revisionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockStore)(nil).GetRevision), postID, revisionID)
+}
+
+// GetRevisions mocks base method.
+func (m *MockStore) GetRevisions(postID string, page repo.Page) ([]types.RevisionSummary, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisions", postID, page)
+	ret0, _ := ret[0].([]types.RevisionSummary)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetRevisions indicates an expected call of GetRevisions.
+func (mr *MockStoreMockRecorder) GetRevisions(postID, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisions", reflect.TypeOf((*MockStore)(nil).GetRevisions), postID, page)
+}
+
+// PatchPost mocks base method.
+func (m *MockStore) PatchPost(patch types.Post, fields []string) (types.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchPost", patch, fields)
+	ret0, _ := ret[0].(types.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchPost indicates an expected call of PatchPost.
+func (mr *MockStoreMockRecorder) PatchPost(patch, fields any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchPost", reflect.TypeOf((*MockStore)(nil).PatchPost), patch, fields)
+}
+
+// PutLike mocks base method.
+func (m *MockStore) PutLike(like types.Like) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutLike", like)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// PutLike indicates an expected call of PutLike.
+func (mr *MockStoreMockRecorder) PutLike(like any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutLike", reflect.TypeOf((*MockStore)(nil).PutLike), like)
+}
+
+// PutPost mocks base method.
+func (m *MockStore) PutPost(post types.Post) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutPost", post)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutPost indicates an expected call of PutPost.
+func (mr *MockStoreMockRecorder) PutPost(post any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutPost", reflect.TypeOf((*MockStore)(nil).PutPost), post)
+}
+
+// UpdatePost mocks base method.
+func (m *MockStore) UpdatePost(post types.Post) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePost", post)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // UpdatePost indicates an expected call of UpdatePost.
-func (mr *MockFirestoreServiceMockRecorder) UpdatePost(post any) *gomock.Call {
+func (mr *MockStoreMockRecorder) UpdatePost(post any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockFirestoreService)(nil).UpdatePost), post)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockStore)(nil).UpdatePost), post)
 }
@@ -9,3 +9,8 @@ type ErrorResponse struct {
 	Message   string `json:"message"`
 	RequestID string `json:"requestId"`
 }
+
+// RestoreRequest names the backup archive to restore. The most recent archive is restored if the name is empty.
+type RestoreRequest struct {
+	Name string `json:"name"`
+}
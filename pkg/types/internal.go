@@ -1,7 +1,10 @@
 package types
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -15,7 +18,28 @@ type Post struct {
 	ContentHTML        string    `json:"contentHtml"`
 	ContentHTMLPreview string    `json:"contentHtmlPreview"`
 	Tags               []string  `json:"tags"`
+	WordCount          int       `json:"wordCount"`
+	ReadingTimeMinutes int       `json:"readingTimeMinutes"`
+	Excerpt            string    `json:"excerpt"`
 	Published          time.Time `json:"published"`
+	Updated            time.Time `json:"updated"`
+}
+
+// Revision is a snapshot of a post, taken before it was changed.
+type Revision struct {
+	ID      string    `json:"id"`
+	Created time.Time `json:"created"`
+	Post    Post      `json:"post"`
+}
+
+// RevisionSummary describes a revision without its content, for listing the revisions of a post.
+// Title, Slug, and Updated are those of the post as it was.
+type RevisionSummary struct {
+	ID      string    `json:"id"`
+	Created time.Time `json:"created"`
+	Title   string    `json:"title"`
+	Slug    string    `json:"slug"`
+	Updated time.Time `json:"updated"`
 }
 
 type Like struct {
@@ -25,6 +49,21 @@ type Like struct {
 	URL       string    `json:"url"`
 }
 
+// Validate verifies a like has a title and an absolute http(s) URL.
+func (l Like) Validate() error {
+	if strings.TrimSpace(l.Title) == "" {
+		return errors.New("title is empty")
+	}
+	u, err := url.Parse(l.URL)
+	if err != nil {
+		return WrapErr(err, "failed to parse url")
+	}
+	if !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url '%s' is not an absolute http(s) url", l.URL)
+	}
+	return nil
+}
+
 // WrapErr wraps an error and returns a new one
 func WrapErr(err error, message string) error {
 	return fmt.Errorf("%s; %w", message, err)